@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jkoelker/posuer/pkg/config"
+	"github.com/jkoelker/posuer/pkg/oauth"
+)
+
+// ErrServerNotFound is returned when the server to authorize is not configured.
+var ErrServerNotFound = errors.New("server not found")
+
+// runAuth runs the interactive OAuth login for the named server.
+func runAuth(configPath string, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: usage: posuer [flags] auth <server>", config.ErrConfigInvalid)
+	}
+
+	serverConfigs, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	name := args[0]
+
+	for _, serverConfig := range serverConfigs {
+		if serverConfig.Name != name {
+			continue
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		if err := oauth.Login(ctx, serverConfig); err != nil {
+			return fmt.Errorf("failed to authorize %s: %w", name, err)
+		}
+
+		log.Printf("Authorized %s", name)
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrServerNotFound, name)
+}
@@ -8,19 +8,28 @@ import (
 	"os/signal"
 	"runtime/debug"
 	"syscall"
+	"time"
 
-	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcp-go/client"
 
 	"github.com/jkoelker/posuer/pkg/config"
 	"github.com/jkoelker/posuer/pkg/interposer"
+	"github.com/jkoelker/posuer/pkg/serve"
 )
 
 func main() {
 	// Parse command line flags
 	configPath := flag.String("config", "", "Path to the configuration file")
-	stdioFlag := flag.Bool("stdio", false, "Run in stdio mode")
+	stdioFlag := flag.Bool("stdio", false, "Run in stdio mode (shorthand for -transport stdio)")
+	transportFlag := flag.String("transport", string(serve.TransportStdio), "Transport to serve clients over (stdio, http, sse)")
+	listenFlag := flag.String("listen", serve.DefaultListenAddress, "Address to listen on for the http and sse transports")
+	baseURLFlag := flag.String("base-url", "", "Public base URL advertised to clients of the sse transport")
 	versionFlag := flag.Bool("version", false, "Show version information")
 	watchFlag := flag.Bool("watch", false, "Watch the config file for changes")
+	initTimeoutFlag := flag.Duration("init-timeout", interposer.DefaultInitTimeout, "Time each backend may take to initialize")
+	startupTimeoutFlag := flag.Duration("startup-timeout", interposer.DefaultStartupTimeout, "Time all backends may take to start")
+	serveImmediatelyFlag := flag.Bool("serve-immediately", false, "Serve clients while backends are still starting")
+	safeModeFlag := flag.Bool("safe-mode", false, "Only expose read-only tools, regardless of the configuration")
 	flag.Parse()
 
 	// Show version and exit if requested
@@ -33,35 +42,66 @@ func main() {
 		return
 	}
 
+	// Run a command instead of serving if one was given
+	if flag.NArg() > 0 {
+		runCommand(*configPath, flag.Args())
+
+		return
+	}
+
+	transport, err := serve.ParseTransport(*transportFlag)
+	if err != nil {
+		log.Fatalf("Invalid transport: %v", err)
+	}
+
+	if *stdioFlag {
+		transport = serve.TransportStdio
+	}
+
 	// Load configuration
 	serverConfigs, err := config.Load(*configPath)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	settings, err := config.LoadSettings(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load settings: %v", err)
+	}
+
 	// Create interposer
 	version, _, _ := getVersionInfo()
 
-	posuer, err := interposer.NewInterposer("Posuer", version)
+	posuer, err := interposer.NewInterposer(
+		"Posuer",
+		version,
+		interposer.WithSafeMode(*safeModeFlag || settings.SafeMode),
+		interposer.WithCollisionPolicy(interposer.CollisionPolicy(settings.CollisionPolicy)),
+	)
 	if err != nil {
 		log.Fatalf("Failed to create interposer: %v", err)
 	}
 
+	// Close the backends once done serving
+	defer func() {
+		if err := posuer.Close(); err != nil {
+			log.Printf("Error closing interposer: %v", err)
+		}
+	}()
+
 	// Set up context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Connect to backend servers
-	for _, serverConfig := range serverConfigs {
-		log.Printf("Connecting to backend server: %s", serverConfig.Name)
-
-		if err := posuer.AddBackend(ctx, serverConfig.Name, serverConfig); err != nil {
-			log.Printf("Warning: failed to connect to %s: %v", serverConfig.Name, err)
-		}
+	// Connect to backend servers, in the background if serving immediately
+	if *serveImmediatelyFlag {
+		go startBackends(ctx, posuer, serverConfigs, *initTimeoutFlag, *startupTimeoutFlag)
+	} else {
+		startBackends(ctx, posuer, serverConfigs, *initTimeoutFlag, *startupTimeoutFlag)
 	}
 
 	// Set up config file watcher if requested
-	setupConfigWatcher(ctx, *watchFlag, *configPath, posuer)
+	setupConfigWatcher(ctx, *watchFlag, *configPath, *safeModeFlag, posuer)
 
 	// Set up signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -73,22 +113,83 @@ func main() {
 		cancel()
 	}()
 
-	// Run in stdio mode
-	if *stdioFlag {
-		log.Printf("Starting in stdio mode")
+	// Serve clients over the selected transport
+	options := []serve.Option{
+		serve.WithAddress(*listenFlag),
+		serve.WithBaseURL(*baseURLFlag),
+		// Resource subscriptions are not handled by the MCP server
+		serve.WithInterceptor(posuer.Intercept),
+	}
+
+	if err := serveTransport(ctx, transport, posuer, options...); err != nil {
+		log.Printf("Server error: %v", err)
+	}
+}
 
-		if err := server.ServeStdio(posuer.Server()); err != nil {
-			log.Printf("Stdio server error: %v", err)
+// startBackends connects to the backend servers concurrently within the startup timeout.
+func startBackends(
+	ctx context.Context,
+	posuer *interposer.Interposer,
+	serverConfigs []config.Server,
+	initTimeout time.Duration,
+	startupTimeout time.Duration,
+) {
+	ctx, cancel := context.WithTimeout(ctx, startupTimeout)
+	defer cancel()
+
+	errs := posuer.StartBackends(ctx, serverConfigs, initTimeout)
+
+	for _, serverConfig := range serverConfigs {
+		err, failed := errs[serverConfig.Name]
+		if !failed {
+			continue
 		}
 
-		return
+		log.Printf("Warning: failed to connect to %s: %v", serverConfig.Name, err)
+
+		if client.IsOAuthAuthorizationRequiredError(err) {
+			log.Printf("Run `posuer auth %s` to authorize it", serverConfig.Name)
+		}
+	}
+
+	log.Printf("Started %d of %d backend servers", len(serverConfigs)-len(errs), len(serverConfigs))
+}
+
+// runCommand runs the command given on the command line and exits.
+func runCommand(configPath string, args []string) {
+	switch args[0] {
+	case "auth":
+		if err := runAuth(configPath, args[1:]); err != nil {
+			log.Fatalf("Authorization failed: %v", err)
+		}
+
+	default:
+		log.Fatalf("Unknown command: %s", args[0])
 	}
+}
+
+// serveTransport serves the interposer over the transport until the context is canceled.
+func serveTransport(
+	ctx context.Context,
+	transport serve.Transport,
+	posuer *interposer.Interposer,
+	options ...serve.Option,
+) error {
+	switch transport {
+	case serve.TransportHTTP:
+		log.Printf("Starting in Streamable HTTP mode")
+
+		return serve.NewHTTP(posuer.Server(), options...).Serve(ctx)
+
+	case serve.TransportSSE:
+		log.Printf("Starting in SSE mode")
 
-	// Default to stdio mode for now
-	log.Printf("Starting in stdio mode (default)")
+		return serve.NewSSE(posuer.Server(), options...).Serve(ctx)
 
-	if err := server.ServeStdio(posuer.Server()); err != nil {
-		log.Printf("Stdio server error: %v", err)
+	default:
+		log.Printf("Starting in stdio mode")
+
+		return serve.Stdio(ctx, posuer.Server(), options...)
 	}
 }
 
@@ -135,6 +236,7 @@ func setupConfigWatcher(
 	ctx context.Context,
 	watchEnabled bool,
 	configPath string,
+	safeMode bool,
 	posuer *interposer.Interposer,
 ) {
 	// Skip if watching is disabled or no config path is provided
@@ -151,6 +253,16 @@ func setupConfigWatcher(
 		return
 	}
 
+	// Safe mode follows the config file, unless forced by the flag
+	watcher.OnSettingsChange(func(settings config.Settings) {
+		posuer.SetSafeMode(safeMode || settings.SafeMode)
+
+		policy := interposer.CollisionPolicy(settings.CollisionPolicy)
+		if err := posuer.Registry().SetCollisionPolicy(policy); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	})
+
 	// Register callback for config changes
 	watcher.OnChange(func(newConfigs []config.Server) {
 		log.Printf("Config file changed, reconfiguring with %d backends", len(newConfigs))
@@ -167,7 +279,6 @@ func setupConfigWatcher(
 		return
 	}
 
+	// The watcher keeps watching until ctx is done, and closes itself then
 	log.Printf("Config file watcher started successfully")
-	// Make sure to close the watcher when we're done
-	defer watcher.Close()
 }
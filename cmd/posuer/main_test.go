@@ -0,0 +1,66 @@
+//nolint:testpackage // Need access to unexported functions for testing
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+	"github.com/jkoelker/posuer/pkg/interposer"
+)
+
+// backendFactory creates in-process clients of a backend offering a
+// read-only tool and a tool that is not.
+func backendFactory(_ config.Server) (client.MCPClient, error) {
+	backend := server.NewMCPServer("backend", "1.0.0", server.WithToolCapabilities(false))
+
+	handler := func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}
+
+	backend.AddTool(mcp.NewTool("read", mcp.WithReadOnlyHintAnnotation(true)), handler)
+	backend.AddTool(mcp.NewTool("write", mcp.WithReadOnlyHintAnnotation(false)), handler)
+
+	return client.NewInProcessClient(backend) //nolint:wrapcheck // Transparent wrapper
+}
+
+func TestConfigWatcherSafeMode(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	servers := "servers:\n  - name: backend\n    command: backend\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(servers), config.FilePermissions))
+
+	serverConfigs, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+
+	posuer, err := interposer.NewInterposer("posuer", "test", interposer.WithClientFactory(backendFactory))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = posuer.Close() })
+
+	require.NoError(t, posuer.Reconfigure(ctx, serverConfigs))
+	require.NotNil(t, posuer.Server().GetTool("backend-write"))
+
+	// The watcher keeps running once set up
+	setupConfigWatcher(ctx, true, configPath, false, posuer)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("safe_mode: true\n"+servers), config.FilePermissions))
+
+	assert.Eventually(t, func() bool {
+		return posuer.Server().GetTool("backend-write") == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.NotNil(t, posuer.Server().GetTool("backend-read"))
+}
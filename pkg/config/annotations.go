@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+)
+
+// Tool annotation hints that capabilities can be filtered on.
+const (
+	// AnnotationReadOnly is set for tools that do not modify their environment.
+	AnnotationReadOnly = "readOnlyHint"
+
+	// AnnotationDestructive is set for tools that may perform destructive updates.
+	AnnotationDestructive = "destructiveHint"
+
+	// AnnotationIdempotent is set for tools whose repeated calls have no additional effect.
+	AnnotationIdempotent = "idempotentHint"
+
+	// AnnotationOpenWorld is set for tools that interact with external entities.
+	AnnotationOpenWorld = "openWorldHint"
+)
+
+// annotationsKey is the key of the annotations in a capability map.
+const annotationsKey = "annotations"
+
+// isAnnotation returns true if the name is a known tool annotation hint.
+func isAnnotation(name string) bool {
+	switch name {
+	case AnnotationReadOnly, AnnotationDestructive, AnnotationIdempotent, AnnotationOpenWorld:
+		return true
+	default:
+		return false
+	}
+}
+
+// processAnnotationsValue processes the annotations of a capability map,
+// which must map known hints to booleans.
+func processAnnotationsValue(val any) (map[string]bool, error) {
+	hints, ok := val.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: annotations must be a map of hints to booleans", ErrConfigInvalid)
+	}
+
+	annotations := make(map[string]bool, len(hints))
+
+	for hint, value := range hints {
+		if !isAnnotation(hint) {
+			return nil, fmt.Errorf("%w: unknown annotation %q", ErrConfigInvalid, hint)
+		}
+
+		enabled, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: annotation %q must be a boolean", ErrConfigInvalid, hint)
+		}
+
+		annotations[hint] = enabled
+	}
+
+	return annotations, nil
+}
+
+// matchesAnnotations returns true if the tool's annotations have every
+// configured hint set to the configured value.
+func matchesAnnotations(configured, annotations map[string]bool) bool {
+	for hint, value := range configured {
+		if annotations[hint] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ToolEnabled returns true if the tool is enabled for the server, taking the
+// annotations filters into account as well as the tool's name.
+// The annotations map each hint to its effective value.
+func (s *Server) ToolEnabled(name string, annotations map[string]bool) bool {
+	if !s.Enabled(CapabilityTypeTool, name) {
+		return false
+	}
+
+	if s.Disable != nil && len(s.Disable.Annotations) > 0 &&
+		matchesAnnotations(s.Disable.Annotations, annotations) {
+		return false
+	}
+
+	if s.Enable != nil && len(s.Enable.Annotations) > 0 &&
+		!matchesAnnotations(s.Enable.Annotations, annotations) {
+		return false
+	}
+
+	return true
+}
+
+// compareAnnotations returns true if both capabilities filter on the same annotations.
+func compareAnnotations(first, second *Capability) bool {
+	return maps.Equal(first.Annotations, second.Annotations)
+}
@@ -0,0 +1,74 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+func TestAnnotationFilters(t *testing.T) {
+	t.Parallel()
+
+	readOnly := map[string]bool{config.AnnotationReadOnly: true, config.AnnotationDestructive: false}
+	destructive := map[string]bool{config.AnnotationReadOnly: false, config.AnnotationDestructive: true}
+	writable := map[string]bool{config.AnnotationReadOnly: false, config.AnnotationDestructive: false}
+
+	t.Run("enable read-only tools", func(t *testing.T) {
+		t.Parallel()
+
+		var server config.Server
+		require.NoError(t, yaml.Unmarshal([]byte("name: test-server\nenable:\n  annotations:\n    readOnlyHint: true"), &server))
+
+		assert.False(t, server.Disabled())
+		assert.True(t, server.ToolEnabled("read", readOnly))
+		assert.False(t, server.ToolEnabled("write", writable))
+		assert.True(t, server.Enabled(config.CapabilityTypePrompt, "prompt"))
+	})
+
+	t.Run("disable destructive tools", func(t *testing.T) {
+		t.Parallel()
+
+		var server config.Server
+		require.NoError(t, yaml.Unmarshal([]byte("name: test-server\ndisable:\n  annotations:\n    destructiveHint: true"), &server))
+
+		assert.True(t, server.ToolEnabled("read", readOnly))
+		assert.True(t, server.ToolEnabled("write", writable))
+		assert.False(t, server.ToolEnabled("delete", destructive))
+	})
+
+	t.Run("combined with names", func(t *testing.T) {
+		t.Parallel()
+
+		yamlStr := "name: test-server\nenable:\n  tools: [read, write]\n  annotations:\n    readOnlyHint: true"
+
+		var server config.Server
+		require.NoError(t, yaml.Unmarshal([]byte(yamlStr), &server))
+
+		assert.True(t, server.ToolEnabled("read", readOnly))
+		assert.False(t, server.ToolEnabled("search", readOnly))
+		assert.False(t, server.ToolEnabled("write", writable))
+	})
+
+	t.Run("unknown hint", func(t *testing.T) {
+		t.Parallel()
+
+		var server config.Server
+
+		err := yaml.Unmarshal([]byte("name: test-server\nenable:\n  annotations:\n    readOnly: true"), &server)
+		require.ErrorIs(t, err, config.ErrConfigInvalid)
+	})
+
+	t.Run("compared", func(t *testing.T) {
+		t.Parallel()
+
+		first := &config.Capability{Annotations: map[string]bool{config.AnnotationReadOnly: true}}
+		second := &config.Capability{Annotations: map[string]bool{config.AnnotationReadOnly: false}}
+
+		assert.True(t, config.CompareCapability(first, first.Clone()))
+		assert.False(t, config.CompareCapability(first, second))
+	})
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+const (
+	// DefaultAuthScheme is the default scheme of the Authorization header.
+	DefaultAuthScheme = "Bearer"
+
+	// headerAuthorization is the name of the Authorization header.
+	headerAuthorization = "Authorization"
+)
+
+// Auth represents the authentication used with a remote server.
+type Auth struct {
+	// Token is sent in the Authorization header of every request.
+	Token Secret `json:"token" yaml:"token"`
+
+	// Scheme is the authorization scheme, defaults to Bearer.
+	Scheme string `json:"scheme" yaml:"scheme"`
+}
+
+// Clone creates a deep copy of the Auth configuration.
+func (a *Auth) Clone() *Auth {
+	if a == nil {
+		return nil
+	}
+
+	clone := *a
+
+	return &clone
+}
+
+// Header returns the value of the Authorization header.
+func (a *Auth) Header() (string, error) {
+	token, err := a.Token.Resolve()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve auth token: %w", err)
+	}
+
+	scheme := a.Scheme
+	if scheme == "" {
+		scheme = DefaultAuthScheme
+	}
+
+	return scheme + " " + token, nil
+}
+
+// RequestHeaders resolves the headers to send with every request to a remote
+// server, including the Authorization header when auth is configured.
+func (s *Server) RequestHeaders() (map[string]string, error) {
+	headers := make(map[string]string, len(s.Headers)+1)
+
+	for name, secret := range s.Headers {
+		value, err := secret.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve header %s: %w", name, err)
+		}
+
+		headers[name] = value
+	}
+
+	if s.Auth != nil {
+		value, err := s.Auth.Header()
+		if err != nil {
+			return nil, err
+		}
+
+		headers[headerAuthorization] = value
+	}
+
+	return headers, nil
+}
+
+// OAuth represents the OAuth 2.1 authorization of a remote server.
+// Without a client ID the client is registered dynamically on first login.
+// It can be set to true to use the defaults, or to false to disable it.
+type OAuth struct {
+	// ClientID is the ID of a pre-registered client.
+	ClientID string `json:"client_id" yaml:"client_id"`
+
+	// ClientSecret is the secret of a pre-registered confidential client.
+	ClientSecret Secret `json:"client_secret" yaml:"client_secret"`
+
+	// Scopes are the scopes to request.
+	Scopes []string `json:"scopes" yaml:"scopes"`
+
+	// RedirectURI is the loopback URI the authorization server redirects to,
+	// defaults to a random port on 127.0.0.1.
+	RedirectURI string `json:"redirect_uri" yaml:"redirect_uri"`
+
+	// MetadataURL overrides discovery of the authorization server metadata.
+	MetadataURL string `json:"metadata_url" yaml:"metadata_url"`
+
+	// disabled is set when OAuth is explicitly turned off with false.
+	disabled bool
+}
+
+// IsEnabled returns true if OAuth is configured and not explicitly disabled.
+func (o *OAuth) IsEnabled() bool {
+	return o != nil && !o.disabled
+}
+
+// Clone creates a deep copy of the OAuth configuration.
+func (o *OAuth) Clone() *OAuth {
+	if o == nil {
+		return nil
+	}
+
+	clone := *o
+
+	if o.Scopes != nil {
+		clone.Scopes = make([]string, len(o.Scopes))
+		copy(clone.Scopes, o.Scopes)
+	}
+
+	return &clone
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (o *OAuth) UnmarshalYAML(value *yaml.Node) error {
+	unmarshalFunc := func(data any, target any) error {
+		node, ok := data.(*yaml.Node)
+		if !ok {
+			return fmt.Errorf("%w: expected *yaml.Node, got %T", ErrConfigInvalid, data)
+		}
+
+		return node.Decode(target)
+	}
+
+	return o.unmarshal(unmarshalFunc, value)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (o *OAuth) UnmarshalJSON(data []byte) error {
+	unmarshalFunc := func(data any, target any) error {
+		bytes, ok := data.([]byte)
+		if !ok {
+			return fmt.Errorf("%w: expected []byte, got %T", ErrConfigInvalid, data)
+		}
+
+		return json.Unmarshal(bytes, target)
+	}
+
+	return o.unmarshal(unmarshalFunc, data)
+}
+
+// unmarshal is a helper function to unmarshal the configuration.
+func (o *OAuth) unmarshal(unmarshalFunc func(data any, target any) error, data any) error {
+	// Try to unmarshal as a boolean, true enables OAuth with defaults and
+	// false disables it
+	var boolValue bool
+	if err := unmarshalFunc(data, &boolValue); err == nil {
+		*o = OAuth{disabled: !boolValue}
+
+		return nil
+	}
+
+	// Try to unmarshal as a full OAuth configuration
+	type OAuthAlias OAuth
+
+	var oauth OAuthAlias
+	if err := unmarshalFunc(data, &oauth); err != nil {
+		return fmt.Errorf("%w: %w", ErrConfigInvalid, err)
+	}
+
+	*o = OAuth(oauth)
+
+	return nil
+}
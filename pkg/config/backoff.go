@@ -0,0 +1,85 @@
+package config
+
+import "time"
+
+const (
+	// DefaultBackoffInitial is the default delay before the first reconnect attempt.
+	DefaultBackoffInitial = time.Second
+
+	// DefaultBackoffMax is the default upper bound of the reconnect delay.
+	DefaultBackoffMax = time.Minute
+
+	// DefaultBackoffMultiplier is the default factor the delay grows by per attempt.
+	DefaultBackoffMultiplier = 2.0
+
+	// DefaultBackoffJitter is the default fraction of the delay that is randomized.
+	DefaultBackoffJitter = 0.2
+)
+
+// Backoff represents the policy for reconnecting to a server that died.
+// Unset fields use the defaults.
+type Backoff struct {
+	// Initial is the delay before the first reconnect attempt.
+	Initial Duration `json:"initial" yaml:"initial"`
+
+	// Max is the upper bound of the delay between attempts.
+	Max Duration `json:"max" yaml:"max"`
+
+	// Multiplier is the factor the delay grows by after each failed attempt.
+	Multiplier float64 `json:"multiplier" yaml:"multiplier"`
+
+	// Jitter is the fraction of the delay that is randomized, between 0 and 1.
+	// Set it to 0 to reconnect at exact delays.
+	Jitter *float64 `json:"jitter" yaml:"jitter"`
+
+	// Attempts limits the number of reconnect attempts, 0 retries forever.
+	Attempts int `json:"attempts" yaml:"attempts"`
+}
+
+// Clone creates a deep copy of the Backoff configuration.
+func (b *Backoff) Clone() *Backoff {
+	if b == nil {
+		return nil
+	}
+
+	clone := *b
+
+	if b.Jitter != nil {
+		jitter := *b.Jitter
+		clone.Jitter = &jitter
+	}
+
+	return &clone
+}
+
+// WithDefaults returns a copy of the configuration with unset fields defaulted.
+// It is safe to call on a nil Backoff.
+func (b *Backoff) WithDefaults() Backoff {
+	var backoff Backoff
+	if b != nil {
+		backoff = *b
+	}
+
+	if backoff.Initial <= 0 {
+		backoff.Initial = Duration(DefaultBackoffInitial)
+	}
+
+	if backoff.Max <= 0 {
+		backoff.Max = Duration(DefaultBackoffMax)
+	}
+
+	if backoff.Max < backoff.Initial {
+		backoff.Max = backoff.Initial
+	}
+
+	if backoff.Multiplier < 1 {
+		backoff.Multiplier = DefaultBackoffMultiplier
+	}
+
+	if backoff.Jitter == nil || *backoff.Jitter < 0 || *backoff.Jitter > 1 {
+		jitter := DefaultBackoffJitter
+		backoff.Jitter = &jitter
+	}
+
+	return backoff
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 
 	yaml "sigs.k8s.io/yaml/goyaml.v3"
 )
@@ -31,6 +32,9 @@ type Capability struct {
 
 	// Capabilities is a map of capability types to lists of capabilities
 	Capabilities map[CapabilityType][]string `json:"capabilities" yaml:"capabilities"`
+
+	// Annotations maps tool annotation hints to the values tools must have
+	Annotations map[string]bool `json:"annotations" yaml:"annotations"`
 }
 
 // Clone creates a deep copy of the Capability instance.
@@ -49,24 +53,20 @@ func (c *Capability) Clone() *Capability {
 		copy(clone.Capabilities[key], value)
 	}
 
+	if c.Annotations != nil {
+		clone.Annotations = maps.Clone(c.Annotations)
+	}
+
 	return clone
 }
 
-// HasCapability checks if a specific capability is in the list of capabilities.
+// HasCapability checks if a specific capability matches the list of capabilities.
 func (c *Capability) HasCapability(capability CapabilityType, name string) bool {
 	if c.All {
 		return true
 	}
 
-	if capabilities, ok := c.Capabilities[capability]; ok {
-		for _, capability := range capabilities {
-			if capability == name {
-				return true
-			}
-		}
-	}
-
-	return false
+	return matchesAny(c.Capabilities[capability], name)
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
@@ -107,6 +107,7 @@ func (c *Capability) unmarshalBool(
 	if err := unmarshalFunc(data, &boolValue); err == nil {
 		c.All = boolValue
 		c.Capabilities = nil
+		c.Annotations = nil
 
 		return true
 	}
@@ -196,6 +197,17 @@ func (c *Capability) unmarshalCapMap(
 	c.Capabilities = make(map[CapabilityType][]string)
 
 	for key, val := range capMap {
+		if key == annotationsKey {
+			annotations, err := processAnnotationsValue(val)
+			if err != nil {
+				return err
+			}
+
+			c.Annotations = annotations
+
+			continue
+		}
+
 		capType := CapabilityType(key)
 
 		strArr, err := processCapabilityValue(val)
@@ -221,11 +233,15 @@ func (c *Capability) unmarshal(
 
 	// Try to unmarshal as a list of strings (tool names)
 	if c.unmarshalToolList(unmarshalFunc, data) {
-		return nil
+		return c.Validate()
 	}
 
 	// Try to unmarshal as a capability map
-	return c.unmarshalCapMap(unmarshalFunc, data)
+	if err := c.unmarshalCapMap(unmarshalFunc, data); err != nil {
+		return err
+	}
+
+	return c.Validate()
 }
 
 // CompareCapability compares two capability configurations to check if they are equivalent.
@@ -245,6 +261,10 @@ func CompareCapability(first, second *Capability) bool {
 		return true
 	}
 
+	if !compareAnnotations(first, second) {
+		return false
+	}
+
 	// Compare Capabilities maps
 	return areCapabilityMapsEqual(first.Capabilities, second.Capabilities)
 }
@@ -70,20 +70,12 @@ func Load(configPath string, opts ...func(*loadOptions)) ([]Server, error) {
 		return LoadConfig(configPath)
 	}
 
-	options := &loadOptions{
-		getUserConfigDir: os.UserConfigDir,
-	}
-
-	for _, opt := range opts {
-		opt(options)
-	}
 	// Try to find config file in standard location
-	configDir, err := options.getUserConfigDir()
+	defaultConfigPath, err := defaultPath(opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user config directory: %w", err)
+		return nil, err
 	}
 
-	defaultConfigPath := filepath.Join(configDir, DefaultConfigDirName, DefaultConfigFileName)
 	if fileExists(defaultConfigPath) {
 		return LoadConfig(defaultConfigPath)
 	}
@@ -96,6 +88,24 @@ func Load(configPath string, opts ...func(*loadOptions)) ([]Server, error) {
 	return LoadConfig(defaultConfigPath)
 }
 
+// defaultPath returns the path of the configuration file in the user's config directory.
+func defaultPath(opts ...func(*loadOptions)) (string, error) {
+	options := &loadOptions{
+		getUserConfigDir: os.UserConfigDir,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	configDir, err := options.getUserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, DefaultConfigDirName, DefaultConfigFileName), nil
+}
+
 // LoadConfig loads the configuration from the specified path.
 func LoadConfig(configPath string) ([]Server, error) {
 	data, err := os.ReadFile(configPath)
@@ -155,9 +165,17 @@ func processConfig(cfg Config, baseDir string) ([]Server, error) {
 				return nil, fmt.Errorf("failed to unmarshal server config: %w", err)
 			}
 
+			if err := server.validateNaming(); err != nil {
+				return nil, err
+			}
+
 			servers = append(servers, server)
 		case Server:
 			// It's already a proper Server
+			if err := value.validateNaming(); err != nil {
+				return nil, err
+			}
+
 			servers = append(servers, value)
 		default:
 			return nil, fmt.Errorf("%w: unsupported server type: %T", ErrConfigInvalid, value)
@@ -170,13 +188,9 @@ func processConfig(cfg Config, baseDir string) ([]Server, error) {
 // includeServersFromFile includes server configurations from the specified file.
 func includeServersFromFile(filePath string, baseDir string) ([]Server, error) {
 	// Resolve path that may contain ~ for home directory
-	if strings.HasPrefix(filePath, "~") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get user home directory: %w", err)
-		}
-
-		filePath = filepath.Join(home, filePath[1:])
+	filePath, err := expandHome(filePath)
+	if err != nil {
+		return nil, err
 	}
 
 	// Handle relative paths
@@ -237,6 +251,20 @@ func convertClaudeConfig(claudeConfig ClaudeConfig) ([]Server, error) {
 	return servers, nil
 }
 
+// expandHome resolves a leading ~ in the path to the user home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	return filepath.Join(home, path[1:]), nil
+}
+
 // fileExists checks if a file exists and is not a directory.
 func fileExists(path string) bool {
 	info, err := os.Stat(path)
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -571,3 +572,61 @@ enable:
 		assert.False(t, server.Enabled(config.CapabilityTypeResource, "resource2"))
 	})
 }
+
+func TestServerSameConnection(t *testing.T) {
+	t.Parallel()
+
+	server := config.Server{
+		Name:    "test-server",
+		Command: "test-server",
+		Args:    []string{"--port", "8080"},
+		Env:     map[string]string{"DEBUG": "1"},
+	}
+
+	other := server.Clone()
+	other.Disable = &config.Capability{All: true}
+
+	assert.True(t, server.SameConnection(&other))
+	assert.False(t, server.SameCapabilities(&other))
+
+	other = server.Clone()
+	other.Env["DEBUG"] = "0"
+
+	assert.False(t, server.SameConnection(&other))
+	assert.True(t, server.SameCapabilities(&other))
+
+	// Naming, timeouts and health checks are updated without reconnecting
+	prefix := "test"
+	other = server.Clone()
+	other.Prefix = &prefix
+	other.Timeouts.Call = config.Duration(time.Minute)
+	other.HealthCheck = &config.HealthCheck{Threshold: 5}
+
+	assert.True(t, server.SameConnection(&other))
+	assert.False(t, server.SameItems(&other))
+
+	// Remote settings only matter to remote backends
+	other = server.Clone()
+	other.Headers = map[string]config.Secret{"Authorization": {Value: "token"}}
+
+	assert.True(t, server.SameConnection(&other))
+	assert.True(t, server.SameItems(&other))
+
+	remote := config.Server{Name: "remote", URL: "https://example.com/mcp"}
+	other = remote.Clone()
+	other.Headers = map[string]config.Secret{"Authorization": {Value: "token"}}
+
+	assert.False(t, remote.SameConnection(&other))
+}
+
+func TestAllowSamplingUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	var server config.Server
+	require.NoError(t, yaml.Unmarshal([]byte("name: summarizer\nallowSampling: true\n"), &server))
+	assert.True(t, server.AllowSampling)
+
+	server = config.Server{}
+	require.NoError(t, yaml.Unmarshal([]byte("name: summarizer\n"), &server))
+	assert.False(t, server.AllowSampling)
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+// Duration is a time.Duration that can be configured as a string such as
+// "30s" or "1m30s", or as a number of seconds.
+type Duration time.Duration
+
+// Duration returns the value as a time.Duration.
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+// String returns the duration formatted as a string.
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	unmarshalFunc := func(data any, target any) error {
+		bytes, ok := data.([]byte)
+		if !ok {
+			return fmt.Errorf("%w: expected []byte, got %T", ErrConfigInvalid, data)
+		}
+
+		return json.Unmarshal(bytes, target)
+	}
+
+	return d.unmarshal(unmarshalFunc, data)
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	unmarshalFunc := func(data any, target any) error {
+		node, ok := data.(*yaml.Node)
+		if !ok {
+			return fmt.Errorf("%w: expected *yaml.Node, got %T", ErrConfigInvalid, data)
+		}
+
+		return node.Decode(target)
+	}
+
+	return d.unmarshal(unmarshalFunc, value)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(d.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal duration: %w", err)
+	}
+
+	return data, nil
+}
+
+// unmarshal is a helper function that handles the common unmarshaling logic.
+func (d *Duration) unmarshal(unmarshalFunc func(data any, target any) error, data any) error {
+	// Try to unmarshal as a number of seconds
+	var seconds float64
+	if err := unmarshalFunc(data, &seconds); err == nil {
+		*d = Duration(seconds * float64(time.Second))
+
+		return nil
+	}
+
+	// Try to unmarshal as a duration string
+	var value string
+	if err := unmarshalFunc(data, &value); err != nil {
+		return fmt.Errorf("%w: duration must be a string or a number of seconds", ErrConfigInvalid)
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%w: invalid duration %q: %w", ErrConfigInvalid, value, err)
+	}
+
+	*d = Duration(duration)
+
+	return nil
+}
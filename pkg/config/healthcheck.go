@@ -0,0 +1,129 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+const (
+	// DefaultHealthCheckInterval is the default time between health checks.
+	DefaultHealthCheckInterval = 30 * time.Second
+
+	// DefaultHealthCheckTimeout is the default time a health check may take.
+	DefaultHealthCheckTimeout = 5 * time.Second
+
+	// DefaultHealthCheckThreshold is the default number of consecutive failed
+	// health checks after which a server is unhealthy.
+	DefaultHealthCheckThreshold = 3
+)
+
+// HealthCheck represents the periodic health checking of a server.
+// Unset fields use the defaults. It can be set to true to use the defaults,
+// or to false to disable it.
+type HealthCheck struct {
+	// Interval is the time between health checks.
+	Interval Duration `json:"interval" yaml:"interval"`
+
+	// Timeout is the time a single health check may take.
+	Timeout Duration `json:"timeout" yaml:"timeout"`
+
+	// Threshold is the number of consecutive failed checks after which
+	// the server is unhealthy.
+	Threshold int `json:"threshold" yaml:"threshold"`
+
+	// disabled is set when health checks are explicitly turned off with false.
+	disabled bool
+}
+
+// IsEnabled returns true if health checks are configured and not explicitly disabled.
+func (h *HealthCheck) IsEnabled() bool {
+	return h != nil && !h.disabled
+}
+
+// Clone creates a deep copy of the HealthCheck configuration.
+func (h *HealthCheck) Clone() *HealthCheck {
+	if h == nil {
+		return nil
+	}
+
+	clone := *h
+
+	return &clone
+}
+
+// WithDefaults returns a copy of the configuration with unset fields defaulted.
+func (h *HealthCheck) WithDefaults() HealthCheck {
+	var check HealthCheck
+	if h != nil {
+		check = *h
+	}
+
+	if check.Interval <= 0 {
+		check.Interval = Duration(DefaultHealthCheckInterval)
+	}
+
+	if check.Timeout <= 0 {
+		check.Timeout = Duration(DefaultHealthCheckTimeout)
+	}
+
+	if check.Threshold <= 0 {
+		check.Threshold = DefaultHealthCheckThreshold
+	}
+
+	return check
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (h *HealthCheck) UnmarshalYAML(value *yaml.Node) error {
+	unmarshalFunc := func(data any, target any) error {
+		node, ok := data.(*yaml.Node)
+		if !ok {
+			return fmt.Errorf("%w: expected *yaml.Node, got %T", ErrConfigInvalid, data)
+		}
+
+		return node.Decode(target)
+	}
+
+	return h.unmarshal(unmarshalFunc, value)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (h *HealthCheck) UnmarshalJSON(data []byte) error {
+	unmarshalFunc := func(data any, target any) error {
+		bytes, ok := data.([]byte)
+		if !ok {
+			return fmt.Errorf("%w: expected []byte, got %T", ErrConfigInvalid, data)
+		}
+
+		return json.Unmarshal(bytes, target)
+	}
+
+	return h.unmarshal(unmarshalFunc, data)
+}
+
+// unmarshal is a helper function to unmarshal the configuration.
+func (h *HealthCheck) unmarshal(unmarshalFunc func(data any, target any) error, data any) error {
+	// Try to unmarshal as a boolean, true enables health checks with
+	// defaults and false disables them
+	var boolValue bool
+	if err := unmarshalFunc(data, &boolValue); err == nil {
+		*h = HealthCheck{disabled: !boolValue}
+
+		return nil
+	}
+
+	// Try to unmarshal as a full health check configuration
+	type HealthCheckAlias HealthCheck
+
+	var check HealthCheckAlias
+	if err := unmarshalFunc(data, &check); err != nil {
+		return fmt.Errorf("%w: %w", ErrConfigInvalid, err)
+	}
+
+	*h = HealthCheck(check)
+
+	return nil
+}
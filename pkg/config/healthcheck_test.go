@@ -0,0 +1,53 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+func TestHealthCheckUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	yamlStr := `
+name: remote
+url: https://example.com/mcp
+healthcheck:
+  interval: 10s
+  timeout: 2s
+  threshold: 5
+`
+
+	var server config.Server
+	require.NoError(t, yaml.Unmarshal([]byte(yamlStr), &server))
+
+	require.NotNil(t, server.HealthCheck)
+	assert.Equal(t, 10*time.Second, server.HealthCheck.Interval.Duration())
+	assert.Equal(t, 2*time.Second, server.HealthCheck.Timeout.Duration())
+	assert.Equal(t, 5, server.HealthCheck.Threshold)
+
+	clone := server.Clone()
+	clone.HealthCheck.Threshold = 1
+	assert.Equal(t, 5, server.HealthCheck.Threshold, "Clone should not share the healthcheck block")
+}
+
+func TestHealthCheckUnmarshalBool(t *testing.T) {
+	t.Parallel()
+
+	var server config.Server
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "local", "healthcheck": true}`), &server))
+
+	require.NotNil(t, server.HealthCheck)
+	assert.Equal(t, config.DefaultHealthCheckThreshold, server.HealthCheck.WithDefaults().Threshold)
+
+	assert.True(t, server.HealthCheck.IsEnabled())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "local", "healthcheck": false}`), &server))
+	assert.False(t, server.HealthCheck.IsEnabled())
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultHTTPEndpointPath is the conventional path of a Streamable HTTP endpoint.
+const DefaultHTTPEndpointPath = "/mcp"
+
+// HTTP represents configuration specific to Streamable HTTP servers.
+type HTTP struct {
+	// Timeout bounds each HTTP request made to the server.
+	Timeout Duration `json:"timeout" yaml:"timeout"`
+
+	// Listen opens a GET stream to receive server initiated messages.
+	Listen bool `json:"listen" yaml:"listen"`
+}
+
+// Clone creates a deep copy of the HTTP configuration.
+func (h *HTTP) Clone() *HTTP {
+	if h == nil {
+		return nil
+	}
+
+	clone := *h
+
+	return &clone
+}
+
+// isStreamableHTTPURL returns true if the URL points at a conventional
+// Streamable HTTP endpoint.
+func isStreamableHTTPURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasSuffix(strings.TrimSuffix(parsed.Path, "/"), DefaultHTTPEndpointPath)
+}
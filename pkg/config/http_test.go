@@ -0,0 +1,123 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+func TestServerTypeDetection(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		server   config.Server
+		expected config.ServerType
+	}{
+		{
+			name:     "command",
+			server:   config.Server{Command: "echo"},
+			expected: config.ServerTypeStdio,
+		},
+		{
+			name:     "sse url",
+			server:   config.Server{URL: "https://example.com/sse"},
+			expected: config.ServerTypeSSE,
+		},
+		{
+			name:     "mcp url",
+			server:   config.Server{URL: "https://example.com/mcp"},
+			expected: config.ServerTypeHTTP,
+		},
+		{
+			name:     "mcp url with trailing slash",
+			server:   config.Server{URL: "https://example.com/api/mcp/"},
+			expected: config.ServerTypeHTTP,
+		},
+		{
+			name:     "http block",
+			server:   config.Server{URL: "https://example.com/rpc", HTTP: &config.HTTP{}},
+			expected: config.ServerTypeHTTP,
+		},
+		{
+			name:     "explicit type",
+			server:   config.Server{Type: config.ServerTypeSSE, URL: "https://example.com/mcp"},
+			expected: config.ServerTypeSSE,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, test.server.ServerType())
+		})
+	}
+}
+
+func TestHTTPUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	yamlStr := `
+name: remote
+type: http
+url: https://example.com/mcp
+http:
+  timeout: 30s
+  listen: true
+`
+
+	var server config.Server
+	require.NoError(t, yaml.Unmarshal([]byte(yamlStr), &server))
+
+	require.NotNil(t, server.HTTP)
+	assert.Equal(t, config.ServerTypeHTTP, server.ServerType())
+	assert.Equal(t, 30*time.Second, server.HTTP.Timeout.Duration())
+	assert.True(t, server.HTTP.Listen)
+
+	clone := server.Clone()
+	clone.HTTP.Listen = false
+	assert.True(t, server.HTTP.Listen, "Clone should not share the HTTP block")
+}
+
+func TestDurationUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		json     string
+		expected time.Duration
+	}{
+		{name: "string", json: `"1m30s"`, expected: 90 * time.Second},
+		{name: "seconds", json: `5`, expected: 5 * time.Second},
+		{name: "fractional seconds", json: `0.5`, expected: 500 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var duration config.Duration
+			require.NoError(t, json.Unmarshal([]byte(test.json), &duration))
+			assert.Equal(t, test.expected, duration.Duration())
+
+			var yamlDuration config.Duration
+			require.NoError(t, yaml.Unmarshal([]byte(test.json), &yamlDuration))
+			assert.Equal(t, test.expected, yamlDuration.Duration())
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		var duration config.Duration
+		err := json.Unmarshal([]byte(`"soon"`), &duration)
+		assert.ErrorIs(t, err, config.ErrConfigInvalid)
+	})
+}
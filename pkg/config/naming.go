@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultSeparator separates the prefix from the names of tools, resources
+	// and resource templates.
+	DefaultSeparator = "-"
+
+	// DefaultPromptSeparator separates the prefix from the names of prompts.
+	DefaultPromptSeparator = "."
+
+	// URISeparator separates the prefix from the URIs of resources and
+	// resource templates.
+	URISeparator = "+"
+)
+
+// validName matches the characters allowed in prefixes and separators, which
+// end up in the names and URI templates clients see.
+var validName = regexp.MustCompile(`^[A-Za-z0-9_.-]*$`)
+
+// validateNaming returns an error if the prefix or separator of the server
+// contains characters that are not allowed in names or URI templates.
+func (s *Server) validateNaming() error {
+	if s.Prefix != nil && !validName.MatchString(*s.Prefix) {
+		return fmt.Errorf("%w: prefix %q of server %s may only contain letters, digits, '_', '.' and '-'",
+			ErrConfigInvalid, *s.Prefix, s.Name)
+	}
+
+	if s.Separator != nil && !validName.MatchString(*s.Separator) {
+		return fmt.Errorf("%w: separator %q of server %s may only contain letters, digits, '_', '.' and '-'",
+			ErrConfigInvalid, *s.Separator, s.Name)
+	}
+
+	return nil
+}
+
+// FileName returns the name of a file named after a server, with an extension.
+// The server's name is escaped so that the file stays in the directory it is
+// joined to, whatever the name contains.
+func FileName(name, ext string) string {
+	return url.PathEscape(name) + ext
+}
+
+// NamePrefix returns the prefix of the names clients see for the server's
+// capabilities, the server's name unless configured otherwise.
+func (s *Server) NamePrefix() string {
+	if s.Prefix != nil {
+		return *s.Prefix
+	}
+
+	return s.Name
+}
+
+// separator returns the separator between the prefix and the names of a capability type.
+func (s *Server) separator(capability CapabilityType) string {
+	if s.Separator != nil {
+		return *s.Separator
+	}
+
+	if capability == CapabilityTypePrompt {
+		return DefaultPromptSeparator
+	}
+
+	return DefaultSeparator
+}
+
+// ExposedName returns the name clients see for one of the server's
+// capabilities, applying its alias and prefix.
+func (s *Server) ExposedName(capability CapabilityType, name string) string {
+	if alias, ok := s.Aliases[name]; ok {
+		name = alias
+	}
+
+	prefix := s.NamePrefix()
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + s.separator(capability) + name
+}
+
+// OriginalName returns the server's own name for a capability from the name
+// clients see. Returns false if the name is not one of the server's.
+func (s *Server) OriginalName(capability CapabilityType, exposed string) (string, bool) {
+	name := exposed
+
+	if prefix := s.NamePrefix(); prefix != "" {
+		trimmed, ok := strings.CutPrefix(exposed, prefix+s.separator(capability))
+		if !ok {
+			return "", false
+		}
+
+		name = trimmed
+	}
+
+	for original, alias := range s.Aliases {
+		if alias == name {
+			return original, true
+		}
+	}
+
+	return name, true
+}
+
+// ExposedURI returns the URI clients see for one of the server's resources
+// or resource templates.
+func (s *Server) ExposedURI(uri string) string {
+	prefix := s.NamePrefix()
+	if prefix == "" {
+		return uri
+	}
+
+	return prefix + URISeparator + uri
+}
+
+// OriginalURI returns the server's own URI for a resource from the URI clients see.
+func (s *Server) OriginalURI(exposed string) string {
+	prefix := s.NamePrefix()
+	if prefix == "" {
+		return exposed
+	}
+
+	return strings.TrimPrefix(exposed, prefix+URISeparator)
+}
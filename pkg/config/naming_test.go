@@ -0,0 +1,144 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+func TestServerNaming(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		yaml       string
+		capability config.CapabilityType
+		original   string
+		exposed    string
+		uri        string
+	}{
+		{
+			name:       "default tool",
+			yaml:       "name: github",
+			capability: config.CapabilityTypeTool,
+			original:   "create_issue",
+			exposed:    "github-create_issue",
+			uri:        "github+repo://issues",
+		},
+		{
+			name:       "default prompt",
+			yaml:       "name: github",
+			capability: config.CapabilityTypePrompt,
+			original:   "review",
+			exposed:    "github.review",
+			uri:        "github+repo://issues",
+		},
+		{
+			name:       "custom prefix and separator",
+			yaml:       "name: github\nprefix: gh\nseparator: _",
+			capability: config.CapabilityTypePrompt,
+			original:   "review",
+			exposed:    "gh_review",
+			uri:        "gh+repo://issues",
+		},
+		{
+			name:       "empty prefix",
+			yaml:       "name: github\nprefix: \"\"",
+			capability: config.CapabilityTypeTool,
+			original:   "create_issue",
+			exposed:    "create_issue",
+			uri:        "repo://issues",
+		},
+		{
+			name:       "alias",
+			yaml:       "name: github\naliases:\n  create_issue: new_issue",
+			capability: config.CapabilityTypeTool,
+			original:   "create_issue",
+			exposed:    "github-new_issue",
+			uri:        "github+repo://issues",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var server config.Server
+			require.NoError(t, yaml.Unmarshal([]byte(test.yaml), &server))
+
+			assert.Equal(t, test.exposed, server.ExposedName(test.capability, test.original))
+
+			original, ok := server.OriginalName(test.capability, test.exposed)
+			require.True(t, ok)
+			assert.Equal(t, test.original, original)
+
+			assert.Equal(t, test.uri, server.ExposedURI("repo://issues"))
+			assert.Equal(t, "repo://issues", server.OriginalURI(test.uri))
+		})
+	}
+
+	t.Run("not the server's", func(t *testing.T) {
+		t.Parallel()
+
+		server := config.Server{Name: "github"}
+
+		_, ok := server.OriginalName(config.CapabilityTypeTool, "gitlab-create_issue")
+		assert.False(t, ok)
+	})
+}
+
+func TestServerNamingValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		yaml  string
+		valid bool
+	}{
+		{name: "default", yaml: "name: github", valid: true},
+		{name: "empty prefix", yaml: "name: github\nprefix: \"\"", valid: true},
+		{name: "custom", yaml: "name: github\nprefix: gh.v2\nseparator: _", valid: true},
+		{name: "template prefix", yaml: "name: github\nprefix: \"{gh}\""},
+		{name: "spaced prefix", yaml: "name: github\nprefix: g h"},
+		{name: "slash separator", yaml: "name: github\nseparator: /"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			content := "servers:\n  - " + strings.ReplaceAll(test.yaml, "\n", "\n    ") + "\n    command: npx\n"
+			require.NoError(t, os.WriteFile(configPath, []byte(content), config.FilePermissions))
+
+			_, err := config.LoadConfig(configPath)
+			if test.valid {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, config.ErrConfigInvalid)
+		})
+	}
+}
+
+func TestFileName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "github.json", config.FileName("github", ".json"))
+	assert.Equal(t, "my%20server.json", config.FileName("my server", ".json"))
+
+	// Names cannot escape the directory files are joined to
+	for _, name := range []string{"../escape", "nested/name", `back\slash`, "..", "/abs"} {
+		path := filepath.Join("/cache", config.FileName(name, ".json"))
+		assert.Equal(t, "/cache", filepath.Dir(path), "name %q", name)
+	}
+}
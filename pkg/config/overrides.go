@@ -0,0 +1,169 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+// Override changes how one of a server's tools is presented to clients.
+type Override struct {
+	// Description replaces the tool's description.
+	Description *string `json:"description" yaml:"description"`
+
+	// AppendDescription is appended to the tool's description.
+	AppendDescription string `json:"append_description" yaml:"append_description"`
+
+	// Annotations set the tool's annotation hints, which are also used to
+	// filter the tool and in safe mode.
+	Annotations map[string]bool `json:"annotations" yaml:"annotations"`
+
+	// Parameters patch the tool's input schema, keyed by parameter name.
+	Parameters map[string]ParameterOverride `json:"parameters" yaml:"parameters"`
+}
+
+// ParameterOverride patches a parameter of a tool's input schema.
+type ParameterOverride struct {
+	// Hide removes the parameter from the schema.
+	Hide bool `json:"hide" yaml:"hide"`
+
+	// Value is sent for a hidden parameter, which is omitted otherwise.
+	Value any `json:"value" yaml:"value"`
+
+	// Rename exposes the parameter under another name.
+	Rename string `json:"rename" yaml:"rename"`
+
+	// Description replaces the parameter's description.
+	Description *string `json:"description" yaml:"description"`
+
+	// Enum restricts the parameter to the values.
+	Enum []any `json:"enum" yaml:"enum"`
+}
+
+// Clone creates a deep copy of the Override.
+func (o *Override) Clone() Override {
+	if o == nil {
+		return Override{}
+	}
+
+	clone := *o
+
+	if o.Description != nil {
+		description := *o.Description
+		clone.Description = &description
+	}
+
+	clone.Annotations = maps.Clone(o.Annotations)
+
+	if o.Parameters != nil {
+		clone.Parameters = make(map[string]ParameterOverride, len(o.Parameters))
+		for name, parameter := range o.Parameters {
+			if parameter.Description != nil {
+				description := *parameter.Description
+				parameter.Description = &description
+			}
+
+			if parameter.Enum != nil {
+				parameter.Enum = append([]any(nil), parameter.Enum...)
+			}
+
+			clone.Parameters[name] = parameter
+		}
+	}
+
+	return clone
+}
+
+// Validate returns an error if the override is inconsistent.
+func (o *Override) Validate() error {
+	for hint := range o.Annotations {
+		if !isAnnotation(hint) {
+			return fmt.Errorf("%w: unknown annotation %q", ErrConfigInvalid, hint)
+		}
+	}
+
+	renamed := make(map[string]string, len(o.Parameters))
+
+	for name, parameter := range o.Parameters {
+		if parameter.Hide && parameter.Rename != "" {
+			return fmt.Errorf("%w: parameter %q can not be both hidden and renamed", ErrConfigInvalid, name)
+		}
+
+		if parameter.Value != nil && !parameter.Hide {
+			return fmt.Errorf("%w: parameter %q has a value but is not hidden", ErrConfigInvalid, name)
+		}
+
+		if parameter.Rename == "" {
+			continue
+		}
+
+		if other, exists := renamed[parameter.Rename]; exists {
+			return fmt.Errorf(
+				"%w: parameters %q and %q are both renamed to %q",
+				ErrConfigInvalid, other, name, parameter.Rename,
+			)
+		}
+
+		renamed[parameter.Rename] = name
+	}
+
+	return nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (o *Override) UnmarshalYAML(value *yaml.Node) error {
+	unmarshalFunc := func(data any, target any) error {
+		node, ok := data.(*yaml.Node)
+		if !ok {
+			return fmt.Errorf("%w: expected *yaml.Node, got %T", ErrConfigInvalid, data)
+		}
+
+		return node.Decode(target)
+	}
+
+	return o.unmarshal(unmarshalFunc, value)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (o *Override) UnmarshalJSON(data []byte) error {
+	unmarshalFunc := func(data any, target any) error {
+		bytes, ok := data.([]byte)
+		if !ok {
+			return fmt.Errorf("%w: expected []byte, got %T", ErrConfigInvalid, data)
+		}
+
+		return json.Unmarshal(bytes, target)
+	}
+
+	return o.unmarshal(unmarshalFunc, data)
+}
+
+// unmarshal is a helper function to unmarshal and validate the override.
+func (o *Override) unmarshal(unmarshalFunc func(data any, target any) error, data any) error {
+	// Avoid recursing into the Unmarshal methods
+	type rawOverride Override
+
+	var raw rawOverride
+	if err := unmarshalFunc(data, &raw); err != nil {
+		return fmt.Errorf("%w: failed to parse override: %w", ErrConfigInvalid, err)
+	}
+
+	override := Override(raw)
+	if err := override.Validate(); err != nil {
+		return err
+	}
+
+	*o = override
+
+	return nil
+}
+
+// ToolOverride returns the override of a tool, by the server's own name of
+// the tool, and whether it has one.
+func (s *Server) ToolOverride(name string) (Override, bool) {
+	override, exists := s.Overrides[name]
+
+	return override, exists
+}
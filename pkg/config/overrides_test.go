@@ -0,0 +1,72 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+func TestOverrideUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	t.Run("yaml", func(t *testing.T) {
+		t.Parallel()
+
+		var server config.Server
+		require.NoError(t, yaml.Unmarshal([]byte(`
+name: github
+overrides:
+  search:
+    description: Searches code.
+    annotations:
+      readOnlyHint: true
+    parameters:
+      q:
+        rename: query
+`), &server))
+
+		override, exists := server.ToolOverride("search")
+		require.True(t, exists)
+		require.NotNil(t, override.Description)
+		assert.Equal(t, "Searches code.", *override.Description)
+		assert.Equal(t, map[string]bool{config.AnnotationReadOnly: true}, override.Annotations)
+		assert.Equal(t, "query", override.Parameters["q"].Rename)
+
+		// Clones do not share the override
+		clone := server.Clone()
+		*clone.Overrides["search"].Description = "Changed"
+		assert.Equal(t, "Searches code.", *override.Description)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		var override config.Override
+		require.NoError(t, json.Unmarshal(
+			[]byte(`{"parameters": {"owner": {"hide": true, "value": "jkoelker"}}}`),
+			&override,
+		))
+		assert.Equal(t, "jkoelker", override.Parameters["owner"].Value)
+	})
+
+	invalid := map[string]string{
+		"unknown annotation":  `{"annotations": {"readOnly": true}}`,
+		"hidden and renamed":  `{"parameters": {"q": {"hide": true, "rename": "query"}}}`,
+		"value without hide":  `{"parameters": {"q": {"value": "posuer"}}}`,
+		"renamed to the same": `{"parameters": {"q": {"rename": "query"}, "search": {"rename": "query"}}}`,
+	}
+
+	for name, data := range invalid {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var override config.Override
+			require.ErrorIs(t, json.Unmarshal([]byte(data), &override), config.ErrConfigInvalid)
+		})
+	}
+}
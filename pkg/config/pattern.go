@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RegexPrefix marks a capability pattern as a regular expression.
+const RegexPrefix = "re:"
+
+// compiledPatterns caches the regular expressions compiled from patterns.
+var compiledPatterns sync.Map //nolint:gochecknoglobals // Patterns are immutable once compiled
+
+// MatchName returns true if the name matches the capability pattern.
+//
+// Patterns are exact names, globs where `*` matches any run of characters,
+// `?` any single character and `[...]` a character class, or regular
+// expressions when prefixed with `re:`. Either form must match the whole name.
+func MatchName(pattern, name string) bool {
+	if pattern == name {
+		return true
+	}
+
+	compiled, err := compilePattern(pattern)
+	if err != nil {
+		return false
+	}
+
+	return compiled.MatchString(name)
+}
+
+// ValidatePattern returns an error if the capability pattern is malformed.
+func ValidatePattern(pattern string) error {
+	_, err := compilePattern(pattern)
+
+	return err
+}
+
+// compilePattern compiles a capability pattern to an anchored regular expression.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := compiledPatterns.Load(pattern); ok {
+		compiled, _ := cached.(*regexp.Regexp)
+
+		return compiled, nil
+	}
+
+	var expr string
+
+	if regex, ok := strings.CutPrefix(pattern, RegexPrefix); ok {
+		expr = regex
+	} else {
+		expr = globExpr(pattern)
+	}
+
+	compiled, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid capability pattern %q: %w", ErrConfigInvalid, pattern, err)
+	}
+
+	compiledPatterns.Store(pattern, compiled)
+
+	return compiled, nil
+}
+
+// globExpr translates a glob to a regular expression.
+func globExpr(glob string) string {
+	var expr strings.Builder
+
+	for idx := 0; idx < len(glob); idx++ {
+		switch char := glob[idx]; char {
+		case '*':
+			expr.WriteString(".*")
+
+		case '?':
+			expr.WriteString(".")
+
+		case '[':
+			end := strings.IndexByte(glob[idx+1:], ']')
+			if end < 0 {
+				expr.WriteString(regexp.QuoteMeta(glob[idx:]))
+
+				return expr.String()
+			}
+
+			class := glob[idx+1 : idx+1+end]
+			if negated, ok := strings.CutPrefix(class, "!"); ok {
+				class = "^" + negated
+			}
+
+			expr.WriteString("[" + class + "]")
+
+			idx += end + 1
+
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+
+	return expr.String()
+}
+
+// matchesAny returns true if the name matches any of the patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if MatchName(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesAnyName returns true if any of the names matches the pattern.
+func matchesAnyName(pattern string, names []string) bool {
+	for _, name := range names {
+		if MatchName(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate returns an error if any of the capability patterns is malformed.
+func (c *Capability) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	for _, patterns := range c.Capabilities {
+		for _, pattern := range patterns {
+			if err := ValidatePattern(pattern); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Unmatched returns the patterns that match none of the names offered by a
+// backend. Only capability types present in names are checked, so that types
+// whose names are not known yet are not reported.
+func (c *Capability) Unmatched(names map[CapabilityType][]string) map[CapabilityType][]string {
+	if c == nil {
+		return nil
+	}
+
+	unmatched := make(map[CapabilityType][]string)
+
+	for capType, patterns := range c.Capabilities {
+		offered, known := names[capType]
+		if !known {
+			continue
+		}
+
+		for _, pattern := range patterns {
+			if !matchesAnyName(pattern, offered) {
+				unmatched[capType] = append(unmatched[capType], pattern)
+			}
+		}
+	}
+
+	return unmatched
+}
@@ -0,0 +1,87 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+func TestMatchName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"read_file", "read_file", true},
+		{"read_file", "read_files", false},
+		{"browser_*", "browser_navigate", true},
+		{"browser_*", "my_browser_navigate", false},
+		{"github_*_issue", "github_create_issue", true},
+		{"github_*_issue", "github_create_issue_comment", false},
+		{"tool?", "tool1", true},
+		{"tool?", "tool12", false},
+		{"tool[12]", "tool2", true},
+		{"tool[!12]", "tool2", false},
+		{"tool[!12]", "tool3", true},
+		{"file.read", "file_read", false},
+		{"re:^(get|list)_.+", "list_issues", true},
+		{"re:(get|list)_.+", "delete_issue", false},
+		{"re:issue", "list_issues", false},
+		{"re:.*issue.*", "list_issues", true},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, config.MatchName(test.pattern, test.name), "%q against %q", test.pattern, test.name)
+	}
+}
+
+func TestCapabilityPatterns(t *testing.T) {
+	t.Parallel()
+
+	t.Run("enable and disable patterns", func(t *testing.T) {
+		t.Parallel()
+
+		yamlStr := "name: test-server\nenable:\n  tools:\n    - browser_*\ndisable:\n  tools:\n    - re:.*_close$"
+
+		var server config.Server
+		require.NoError(t, yaml.Unmarshal([]byte(yamlStr), &server))
+
+		assert.True(t, server.Enabled(config.CapabilityTypeTool, "browser_navigate"))
+		assert.False(t, server.Enabled(config.CapabilityTypeTool, "browser_close"))
+		assert.False(t, server.Enabled(config.CapabilityTypeTool, "github_create_issue"))
+	})
+
+	t.Run("invalid regex", func(t *testing.T) {
+		t.Parallel()
+
+		var server config.Server
+
+		err := yaml.Unmarshal([]byte("name: test-server\ndisable:\n  - re:(unclosed"), &server)
+		require.ErrorIs(t, err, config.ErrConfigInvalid)
+	})
+
+	t.Run("unmatched patterns", func(t *testing.T) {
+		t.Parallel()
+
+		capability := &config.Capability{
+			Capabilities: map[config.CapabilityType][]string{
+				config.CapabilityTypeTool:   {"browser_*", "brwoser_*"},
+				config.CapabilityTypePrompt: {"missing"},
+			},
+		}
+
+		unmatched := capability.Unmatched(map[config.CapabilityType][]string{
+			config.CapabilityTypeTool: {"browser_navigate", "browser_close"},
+		})
+
+		assert.Equal(t, map[config.CapabilityType][]string{
+			config.CapabilityTypeTool: {"brwoser_*"},
+		}, unmatched)
+	})
+}
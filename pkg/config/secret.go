@@ -0,0 +1,121 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+// ErrSecretNotFound is returned when a secret cannot be resolved.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// Secret represents a sensitive configuration value.
+// It can be given inline as a string, or read from an environment variable
+// or a file so that it does not have to live in the configuration file.
+type Secret struct {
+	// Value is the inline value of the secret.
+	Value string `json:"value" yaml:"value"`
+
+	// Env is the name of the environment variable holding the secret.
+	Env string `json:"env" yaml:"env"`
+
+	// File is the path of the file holding the secret.
+	File string `json:"file" yaml:"file"`
+}
+
+// Resolve returns the value of the secret.
+// Values read from files have surrounding whitespace trimmed.
+func (s Secret) Resolve() (string, error) {
+	switch {
+	case s.Env != "":
+		value, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf("%w: environment variable %s is not set", ErrSecretNotFound, s.Env)
+		}
+
+		return value, nil
+
+	case s.File != "":
+		path, err := expandHome(s.File)
+		if err != nil {
+			return "", err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("%w: failed to read %s: %w", ErrSecretNotFound, path, err)
+		}
+
+		return strings.TrimSpace(string(data)), nil
+
+	default:
+		return s.Value, nil
+	}
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (s *Secret) UnmarshalYAML(value *yaml.Node) error {
+	unmarshalFunc := func(data any, target any) error {
+		node, ok := data.(*yaml.Node)
+		if !ok {
+			return fmt.Errorf("%w: expected *yaml.Node, got %T", ErrConfigInvalid, data)
+		}
+
+		return node.Decode(target)
+	}
+
+	return s.unmarshal(unmarshalFunc, value)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	unmarshalFunc := func(data any, target any) error {
+		bytes, ok := data.([]byte)
+		if !ok {
+			return fmt.Errorf("%w: expected []byte, got %T", ErrConfigInvalid, data)
+		}
+
+		return json.Unmarshal(bytes, target)
+	}
+
+	return s.unmarshal(unmarshalFunc, data)
+}
+
+// unmarshal is a helper function to unmarshal the configuration.
+func (s *Secret) unmarshal(unmarshalFunc func(data any, target any) error, data any) error {
+	// Try to unmarshal as an inline value
+	var value string
+	if err := unmarshalFunc(data, &value); err == nil {
+		*s = Secret{Value: value}
+
+		return nil
+	}
+
+	// Try to unmarshal as a full secret configuration
+	type SecretAlias Secret
+
+	var secret SecretAlias
+	if err := unmarshalFunc(data, &secret); err != nil {
+		return fmt.Errorf("%w: %w", ErrConfigInvalid, err)
+	}
+
+	sources := 0
+
+	for _, source := range []string{secret.Value, secret.Env, secret.File} {
+		if source != "" {
+			sources++
+		}
+	}
+
+	if sources > 1 {
+		return fmt.Errorf("%w: secret must set only one of value, env or file", ErrConfigInvalid)
+	}
+
+	*s = Secret(secret)
+
+	return nil
+}
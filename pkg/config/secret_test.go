@@ -0,0 +1,147 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+func TestSecretUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	t.Run("inline", func(t *testing.T) {
+		t.Parallel()
+
+		var secret config.Secret
+		require.NoError(t, yaml.Unmarshal([]byte(`hunter2`), &secret))
+		assert.Equal(t, config.Secret{Value: "hunter2"}, secret)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Parallel()
+
+		var secret config.Secret
+		require.NoError(t, yaml.Unmarshal([]byte(`env: API_TOKEN`), &secret))
+		assert.Equal(t, config.Secret{Env: "API_TOKEN"}, secret)
+	})
+
+	t.Run("multiple sources", func(t *testing.T) {
+		t.Parallel()
+
+		var secret config.Secret
+		err := yaml.Unmarshal([]byte("env: API_TOKEN\nfile: /token"), &secret)
+		assert.ErrorIs(t, err, config.ErrConfigInvalid)
+	})
+}
+
+func TestSecretResolve(t *testing.T) {
+	t.Setenv("POSUER_TEST_SECRET", "from-env")
+
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+
+	value, err := config.Secret{Value: "inline"}.Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, "inline", value)
+
+	value, err = config.Secret{Env: "POSUER_TEST_SECRET"}.Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", value)
+
+	value, err = config.Secret{File: path}.Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", value)
+
+	_, err = config.Secret{Env: "POSUER_TEST_SECRET_MISSING"}.Resolve()
+	require.ErrorIs(t, err, config.ErrSecretNotFound)
+
+	_, err = config.Secret{File: filepath.Join(t.TempDir(), "missing")}.Resolve()
+	require.ErrorIs(t, err, config.ErrSecretNotFound)
+}
+
+func TestServerRequestHeaders(t *testing.T) {
+	t.Setenv("POSUER_TEST_TOKEN", "s3cret")
+
+	yamlStr := `
+name: remote
+url: https://example.com/mcp
+headers:
+  X-Api-Key: abc123
+  X-Tenant:
+    value: acme
+auth:
+  token:
+    env: POSUER_TEST_TOKEN
+`
+
+	var server config.Server
+	require.NoError(t, yaml.Unmarshal([]byte(yamlStr), &server))
+
+	headers, err := server.RequestHeaders()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"X-Api-Key":     "abc123",
+		"X-Tenant":      "acme",
+		"Authorization": "Bearer s3cret",
+	}, headers)
+
+	server.Auth.Scheme = "Token"
+
+	headers, err = server.RequestHeaders()
+	require.NoError(t, err)
+	assert.Equal(t, "Token s3cret", headers["Authorization"])
+
+	server.Auth.Token = config.Secret{Env: "POSUER_TEST_TOKEN_MISSING"}
+
+	_, err = server.RequestHeaders()
+	assert.ErrorIs(t, err, config.ErrSecretNotFound)
+}
+
+func TestOAuthUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	t.Run("boolean", func(t *testing.T) {
+		t.Parallel()
+
+		var server config.Server
+		require.NoError(t, yaml.Unmarshal([]byte("name: remote\noauth: true"), &server))
+		assert.Equal(t, &config.OAuth{}, server.OAuth)
+		assert.True(t, server.OAuth.IsEnabled())
+
+		require.NoError(t, yaml.Unmarshal([]byte("name: remote\noauth: false"), &server))
+		assert.False(t, server.OAuth.IsEnabled())
+	})
+
+	t.Run("map", func(t *testing.T) {
+		t.Parallel()
+
+		yamlStr := `
+name: remote
+oauth:
+  client_id: posuer
+  client_secret:
+    env: REMOTE_CLIENT_SECRET
+  scopes: [read, write]
+  redirect_uri: http://127.0.0.1:8765/callback
+`
+
+		var server config.Server
+		require.NoError(t, yaml.Unmarshal([]byte(yamlStr), &server))
+		assert.Equal(t, &config.OAuth{
+			ClientID:     "posuer",
+			ClientSecret: config.Secret{Env: "REMOTE_CLIENT_SECRET"},
+			Scopes:       []string{"read", "write"},
+			RedirectURI:  "http://127.0.0.1:8765/callback",
+		}, server.OAuth)
+
+		clone := server.Clone()
+		clone.OAuth.Scopes[0] = "admin"
+		assert.Equal(t, "read", server.OAuth.Scopes[0])
+	})
+}
@@ -1,5 +1,11 @@
 package config
 
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
 // ServerType represents the type of MCP server connection.
 type ServerType string
 
@@ -8,19 +14,36 @@ const (
 	ServerTypeStdio ServerType = "stdio"
 	// ServerTypeSSE represents an SSE-based MCP server.
 	ServerTypeSSE ServerType = "sse"
+	// ServerTypeHTTP represents a Streamable HTTP-based MCP server.
+	ServerTypeHTTP ServerType = "http"
 )
 
 // Server represents a single MCP server configuration.
 type Server struct {
-	Name      string            `json:"name"      yaml:"name"`
-	Type      ServerType        `json:"type"      yaml:"type"`
-	Command   string            `json:"command"   yaml:"command"`
-	Args      []string          `json:"args"      yaml:"args"`
-	Env       map[string]string `json:"env"       yaml:"env"`
-	URL       string            `json:"url"       yaml:"url"`
-	Enable    *Capability       `json:"enable"    yaml:"enable"`
-	Disable   *Capability       `json:"disable"   yaml:"disable"`
-	Container *Container        `json:"container" yaml:"container"`
+	Name          string              `json:"name"          yaml:"name"`
+	Type          ServerType          `json:"type"          yaml:"type"`
+	Command       string              `json:"command"       yaml:"command"`
+	Args          []string            `json:"args"          yaml:"args"`
+	Env           map[string]string   `json:"env"           yaml:"env"`
+	URL           string              `json:"url"           yaml:"url"`
+	Enable        *Capability         `json:"enable"        yaml:"enable"`
+	Disable       *Capability         `json:"disable"       yaml:"disable"`
+	Container     *Container          `json:"container"     yaml:"container"`
+	HTTP          *HTTP               `json:"http"          yaml:"http"`
+	Headers       map[string]Secret   `json:"headers"       yaml:"headers"`
+	Auth          *Auth               `json:"auth"          yaml:"auth"`
+	OAuth         *OAuth              `json:"oauth"         yaml:"oauth"`
+	Backoff       *Backoff            `json:"backoff"       yaml:"backoff"`
+	HealthCheck   *HealthCheck        `json:"healthcheck"   yaml:"healthcheck"`
+	Lazy          bool                `json:"lazy"          yaml:"lazy"`
+	IdleTimeout   Duration            `json:"idleTimeout"   yaml:"idleTimeout"` //nolint:tagliatelle
+	SafeTools     []string            `json:"safe_tools"    yaml:"safe_tools"`
+	Prefix        *string             `json:"prefix"        yaml:"prefix"`
+	Separator     *string             `json:"separator"     yaml:"separator"`
+	Aliases       map[string]string   `json:"aliases"       yaml:"aliases"`
+	Overrides     map[string]Override `json:"overrides"     yaml:"overrides"`
+	Timeouts      Timeouts            `json:"timeouts"      yaml:"timeouts"`
+	AllowSampling bool                `json:"allowSampling" yaml:"allowSampling"` //nolint:tagliatelle
 }
 
 // Clone creates a deep copy of the Server.
@@ -55,9 +78,118 @@ func (s *Server) Clone() Server {
 		server.Container = s.Container.Clone()
 	}
 
+	if s.HTTP != nil {
+		server.HTTP = s.HTTP.Clone()
+	}
+
+	if s.Headers != nil {
+		server.Headers = make(map[string]Secret, len(s.Headers))
+		for k, v := range s.Headers {
+			server.Headers[k] = v
+		}
+	}
+
+	if s.Auth != nil {
+		server.Auth = s.Auth.Clone()
+	}
+
+	if s.OAuth != nil {
+		server.OAuth = s.OAuth.Clone()
+	}
+
+	if s.Backoff != nil {
+		server.Backoff = s.Backoff.Clone()
+	}
+
+	if s.HealthCheck != nil {
+		server.HealthCheck = s.HealthCheck.Clone()
+	}
+
+	if s.Prefix != nil {
+		prefix := *s.Prefix
+		server.Prefix = &prefix
+	}
+
+	if s.Separator != nil {
+		separator := *s.Separator
+		server.Separator = &separator
+	}
+
+	if s.Aliases != nil {
+		server.Aliases = make(map[string]string, len(s.Aliases))
+		for k, v := range s.Aliases {
+			server.Aliases[k] = v
+		}
+	}
+
+	if s.Overrides != nil {
+		server.Overrides = make(map[string]Override, len(s.Overrides))
+		for k, v := range s.Overrides {
+			server.Overrides[k] = v.Clone()
+		}
+	}
+
+	if s.SafeTools != nil {
+		server.SafeTools = make([]string, len(s.SafeTools))
+		copy(server.SafeTools, s.SafeTools)
+	}
+
 	return server
 }
 
+// SameConnection returns true if both configurations connect to the backend
+// the same way: they launch the same command or container, or reach the same
+// remote server with the same credentials, through the same kind of client.
+func (s *Server) SameConnection(other *Server) bool {
+	if s.ServerType() != other.ServerType() ||
+		s.Command != other.Command ||
+		!slices.Equal(s.Args, other.Args) ||
+		!maps.Equal(s.Env, other.Env) ||
+		s.URL != other.URL ||
+		!reflect.DeepEqual(s.Container, other.Container) {
+		return false
+	}
+
+	// Whether the client starts lazily and may relay sampling is decided
+	// when it is created
+	if s.Lazy != other.Lazy || s.IdleTimeout != other.IdleTimeout || s.AllowSampling != other.AllowSampling {
+		return false
+	}
+
+	if s.ServerType() == ServerTypeStdio {
+		return true
+	}
+
+	return reflect.DeepEqual(s.HTTP, other.HTTP) &&
+		reflect.DeepEqual(s.Headers, other.Headers) &&
+		reflect.DeepEqual(s.Auth, other.Auth) &&
+		reflect.DeepEqual(s.OAuth, other.OAuth)
+}
+
+// SameItems returns true if both configurations expose the backend's items
+// the same way, under the same names, with the same overrides and timeouts.
+func (s *Server) SameItems(other *Server) bool {
+	return reflect.DeepEqual(s.Prefix, other.Prefix) &&
+		reflect.DeepEqual(s.Separator, other.Separator) &&
+		maps.Equal(s.Aliases, other.Aliases) &&
+		reflect.DeepEqual(s.Overrides, other.Overrides) &&
+		s.Timeouts == other.Timeouts
+}
+
+// SameCapabilities returns true if both configurations enable and disable
+// the same capabilities.
+func (s *Server) SameCapabilities(other *Server) bool {
+	return CompareCapability(s.Enable, other.Enable) &&
+		CompareCapability(s.Disable, other.Disable) &&
+		areCapabilityListsEqual(s.SafeTools, other.SafeTools)
+}
+
+// SafeTool returns true if the tool may be used in safe mode, either because
+// it is annotated as read-only or because it matches the server's safe tools.
+func (s *Server) SafeTool(name string, annotations map[string]bool) bool {
+	return annotations[AnnotationReadOnly] || matchesAny(s.SafeTools, name)
+}
+
 // ServerType return the type of the server.
 func (s *Server) ServerType() ServerType {
 	if s.Type != "" {
@@ -65,6 +197,12 @@ func (s *Server) ServerType() ServerType {
 	}
 
 	if s.URL != "" {
+		// Streamable HTTP servers are conventionally served on /mcp, anything
+		// else is assumed to be a legacy SSE server
+		if s.HTTP != nil || isStreamableHTTPURL(s.URL) {
+			return ServerTypeHTTP
+		}
+
 		return ServerTypeSSE
 	}
 
@@ -97,23 +235,19 @@ func (s *Server) Enabled(capability CapabilityType, name string) bool {
 
 	// If we have enable.Capabilities map
 	if s.Enable.Capabilities != nil {
-		// Check if this specific capability+name is in the enabled list
+		// Check if this specific capability+name matches the enabled list
 		if enabled, ok := s.Enable.Capabilities[capability]; ok {
-			for _, enabledName := range enabled {
-				if enabledName == name {
-					return true
-				}
-			}
-			// The capability type exists in Enable.Capabilities but this
-			// specific name is not in the list
-			return false
+			// The capability type exists in Enable.Capabilities, so the
+			// name must match one of its patterns
+			return matchesAny(enabled, name)
 		}
 	}
 
 	// If enable exists but doesn't contain this capability type,
-	// we're using a whitelist approach, so return false
+	// we're using a whitelist approach, so return false. Annotations
+	// enable tools by their hints, which ToolEnabled checks
 	if len(s.Enable.Capabilities) > 0 {
-		return false
+		return capability == CapabilityTypeTool && len(s.Enable.Annotations) > 0
 	}
 
 	// Empty enable configuration means nothing is explicitly enabled,
@@ -131,7 +265,8 @@ func (s *Server) hasEmptyEnabledCapabilities() bool {
 	return s.Enable != nil &&
 		!s.Enable.All &&
 		s.Enable.Capabilities != nil &&
-		len(s.Enable.Capabilities) == 0
+		len(s.Enable.Capabilities) == 0 &&
+		len(s.Enable.Annotations) == 0
 }
 
 // hasAllEmptyCapabilityLists checks if Enable has only empty capability lists.
@@ -140,6 +275,11 @@ func (s *Server) hasAllEmptyCapabilityLists() bool {
 		return false
 	}
 
+	// Enabling tools by their annotations enables something
+	if len(s.Enable.Annotations) > 0 {
+		return false
+	}
+
 	// Check if all capability lists are empty
 	for _, capList := range s.Enable.Capabilities {
 		if len(capList) > 0 {
@@ -161,12 +301,8 @@ func (s *Server) disabled(capability CapabilityType, name string) bool {
 			return true
 		}
 
-		if disabled, ok := s.Disable.Capabilities[capability]; ok {
-			for _, disabledName := range disabled {
-				if disabledName == name {
-					return true
-				}
-			}
+		if matchesAny(s.Disable.Capabilities[capability], name) {
+			return true
 		}
 	}
 
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+// Settings are the top-level options of the configuration file, applying to
+// posuer as a whole rather than to a single server.
+type Settings struct {
+	// SafeMode only exposes tools annotated as read-only or allow-listed
+	// per server, and rejects calls to any other tool.
+	SafeMode bool `json:"safe_mode" yaml:"safe_mode"`
+
+	// CollisionPolicy resolves capabilities that several servers expose
+	// under the same name: error, first-wins, last-wins or auto-suffix.
+	CollisionPolicy string `json:"collision_policy" yaml:"collision_policy"`
+}
+
+// LoadSettings loads the settings from the specified path or default location,
+// the same file Load loads the servers from.
+func LoadSettings(configPath string, opts ...func(*loadOptions)) (Settings, error) {
+	if configPath == "" {
+		defaultConfigPath, err := defaultPath(opts...)
+		if err != nil {
+			return Settings{}, err
+		}
+
+		configPath = defaultConfigPath
+	}
+
+	if !fileExists(configPath) {
+		return Settings{}, fmt.Errorf("%w: %s", ErrConfigNotFound, configPath)
+	}
+
+	return loadSettings(configPath)
+}
+
+// loadSettings loads the settings from the specified path.
+// Configurations without settings, such as Claude Desktop's, have the defaults.
+func loadSettings(configPath string) (Settings, error) {
+	var settings Settings
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return settings, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if strings.ToLower(filepath.Ext(configPath)) == ".json" {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return settings, fmt.Errorf("failed to parse JSON config: %w", err)
+		}
+
+		return settings, nil
+	}
+
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("failed to parse YAML config: %w", err)
+	}
+
+	return settings, nil
+}
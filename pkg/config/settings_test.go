@@ -0,0 +1,48 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+func TestLoadSettings(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+
+	t.Run("yaml", func(t *testing.T) {
+		t.Parallel()
+
+		configPath := filepath.Join(tempDir, "config.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte("safe_mode: true\ncollision_policy: first-wins\nservers: []\n"), 0o600))
+
+		settings, err := config.LoadSettings(configPath)
+		require.NoError(t, err)
+		assert.True(t, settings.SafeMode)
+		assert.Equal(t, "first-wins", settings.CollisionPolicy)
+	})
+
+	t.Run("claude desktop", func(t *testing.T) {
+		t.Parallel()
+
+		configPath := filepath.Join(tempDir, "claude.json")
+		require.NoError(t, os.WriteFile(configPath, []byte(`{"mcpServers": {"test": {"command": "test"}}}`), 0o600))
+
+		settings, err := config.LoadSettings(configPath)
+		require.NoError(t, err)
+		assert.False(t, settings.SafeMode)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := config.LoadSettings(filepath.Join(tempDir, "missing.yaml"))
+		require.ErrorIs(t, err, config.ErrConfigNotFound)
+	})
+}
@@ -0,0 +1,16 @@
+package config
+
+// Timeouts bound the requests made to a server. Unset fields do not time out.
+type Timeouts struct {
+	// Initialize bounds starting and initializing the connection.
+	Initialize Duration `json:"initialize" yaml:"initialize"`
+
+	// List bounds listing tools, prompts, resources and templates.
+	List Duration `json:"list" yaml:"list"`
+
+	// Call bounds calling a tool and getting a prompt.
+	Call Duration `json:"call" yaml:"call"`
+
+	// Read bounds reading a resource.
+	Read Duration `json:"read" yaml:"read"`
+}
@@ -0,0 +1,63 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+func TestTimeoutsUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	yamlStr := `
+name: slow
+command: slow-server
+timeouts:
+  initialize: 1m
+  list: 10s
+  call: 2m30s
+`
+
+	var server config.Server
+	require.NoError(t, yaml.Unmarshal([]byte(yamlStr), &server))
+
+	assert.Equal(t, time.Minute, server.Timeouts.Initialize.Duration())
+	assert.Equal(t, 10*time.Second, server.Timeouts.List.Duration())
+	assert.Equal(t, 150*time.Second, server.Timeouts.Call.Duration())
+	assert.Zero(t, server.Timeouts.Read)
+
+	jsonStr := `{"name": "slow", "command": "slow-server", "timeouts": {"read": 5, "call": "1s"}}`
+
+	server = config.Server{}
+	require.NoError(t, json.Unmarshal([]byte(jsonStr), &server))
+
+	assert.Equal(t, 5*time.Second, server.Timeouts.Read.Duration())
+	assert.Equal(t, time.Second, server.Timeouts.Call.Duration())
+	assert.Zero(t, server.Timeouts.Initialize)
+
+	invalid := `
+name: slow
+command: slow-server
+timeouts:
+  call: soon
+`
+	require.ErrorIs(t, yaml.Unmarshal([]byte(invalid), &server), config.ErrConfigInvalid)
+}
+
+func TestIdleTimeoutUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	var server config.Server
+	require.NoError(t, yaml.Unmarshal([]byte("name: lazy\nlazy: true\nidleTimeout: 10m\n"), &server))
+	assert.Equal(t, 10*time.Minute, server.IdleTimeout.Duration())
+
+	server = config.Server{}
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "lazy", "lazy": true, "idleTimeout": 30}`), &server))
+	assert.Equal(t, 30*time.Second, server.IdleTimeout.Duration())
+}
@@ -21,7 +21,9 @@ type Watcher struct {
 	configPath string
 	watcher    *fsnotify.Watcher
 	callbacks  []func([]Server)
-	mutex      sync.RWMutex
+	// Callbacks for the top-level settings
+	settingsCallbacks []func(Settings)
+	mutex             sync.RWMutex
 	// Debounce mechanism
 	debounceInterval time.Duration
 	debounceTimer    *time.Timer
@@ -74,6 +76,14 @@ func (cw *Watcher) OnChange(callback func([]Server)) {
 	cw.callbacks = append(cw.callbacks, callback)
 }
 
+// OnSettingsChange registers a callback to be called with the settings when
+// the config file changes. It is called before the OnChange callbacks.
+func (cw *Watcher) OnSettingsChange(callback func(Settings)) {
+	cw.mutex.Lock()
+	defer cw.mutex.Unlock()
+	cw.settingsCallbacks = append(cw.settingsCallbacks, callback)
+}
+
 // SetDebounceInterval sets the debounce interval for file change events.
 func (cw *Watcher) SetDebounceInterval(interval time.Duration) {
 	cw.mutex.Lock()
@@ -87,6 +97,10 @@ func (cw *Watcher) Close() error {
 		return fmt.Errorf("failed to close watcher: %w", err)
 	}
 
+	// Also closed by the watch loop once its context is done
+	cw.mutex.Lock()
+	defer cw.mutex.Unlock()
+
 	if cw.debounceTimer != nil {
 		cw.debounceTimer.Stop()
 		cw.debounceTimer = nil
@@ -122,10 +136,21 @@ func (cw *Watcher) reloadConfig() {
 		return
 	}
 
+	settings, err := loadSettings(cw.configPath)
+	if err != nil {
+		log.Printf("Error reloading settings: %v", err)
+
+		return
+	}
+
 	// Notify all registered callbacks
 	cw.mutex.RLock()
 	defer cw.mutex.RUnlock()
 
+	for _, callback := range cw.settingsCallbacks {
+		callback(settings)
+	}
+
 	for _, callback := range cw.callbacks {
 		callback(serverConfigs)
 	}
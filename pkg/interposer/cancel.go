@@ -0,0 +1,153 @@
+package interposer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jkoelker/posuer/pkg/isolate"
+)
+
+// requestIDHeader carries the ID of a request from the server hooks to its
+// handler, which is not given the ID. It is removed before the request is
+// forwarded to the backend.
+const requestIDHeader = "Posuer-Request-Id"
+
+// ErrRequestCancelled is the cause of the context of a request the client cancelled.
+var ErrRequestCancelled = errors.New("request cancelled by client")
+
+// requests tracks the requests being forwarded to backends, so that the
+// client can cancel them with notifications/cancelled. Cancelling the
+// context of a request cancels it on the backend, see isolate.Noop.
+type requests struct {
+	mu      sync.Mutex // protects cancels
+	cancels map[string]context.CancelCauseFunc
+}
+
+// newRequests creates a new request tracker.
+func newRequests() *requests {
+	return &requests{cancels: make(map[string]context.CancelCauseFunc)}
+}
+
+// hooks adds the server hooks tagging cancellable requests with their ID.
+func (r *requests) hooks(hooks *server.Hooks) {
+	hooks.AddBeforeCallTool(func(_ context.Context, id any, request *mcp.CallToolRequest) {
+		request.Header = tagRequest(request.Header, id)
+	})
+	hooks.AddBeforeGetPrompt(func(_ context.Context, id any, request *mcp.GetPromptRequest) {
+		request.Header = tagRequest(request.Header, id)
+	})
+	hooks.AddBeforeReadResource(func(_ context.Context, id any, request *mcp.ReadResourceRequest) {
+		request.Header = tagRequest(request.Header, id)
+	})
+}
+
+// tagRequest returns a copy of the headers of a request carrying its ID.
+// The headers are copied as they may belong to the client's HTTP request.
+func tagRequest(header http.Header, id any) http.Header {
+	if header == nil {
+		header = make(http.Header)
+	} else {
+		header = header.Clone()
+	}
+
+	header.Set(requestIDHeader, requestID(id))
+
+	return header
+}
+
+// requestID returns the normalized representation of a request ID.
+func requestID(id any) string {
+	if requestID, ok := id.(mcp.RequestId); ok {
+		return requestID.String()
+	}
+
+	return mcp.NewRequestId(id).String()
+}
+
+// requestKey returns the key of a request, whose ID is only unique within
+// the client's session.
+func requestKey(ctx context.Context, id string) string {
+	var sessionID string
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		sessionID = session.SessionID()
+	}
+
+	return sessionID + "/" + id
+}
+
+// track returns the context of a request tagged by the hooks, canceled when
+// the client cancels the request, and a function untracking it once handled.
+func (r *requests) track(ctx context.Context, header http.Header) (context.Context, func()) {
+	id := header.Get(requestIDHeader)
+	if id == "" {
+		return ctx, func() {}
+	}
+
+	// The backend must not see the tag
+	header.Del(requestIDHeader)
+
+	key := requestKey(ctx, id)
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	r.mu.Lock()
+	r.cancels[key] = cancel
+	r.mu.Unlock()
+
+	return ctx, func() {
+		r.mu.Lock()
+		delete(r.cancels, key)
+		r.mu.Unlock()
+
+		cancel(nil)
+	}
+}
+
+// cancelled handles notifications/cancelled from the client, canceling the
+// request if it is still being handled.
+func (r *requests) cancelled(ctx context.Context, notification mcp.JSONRPCNotification) {
+	id, exists := notification.Params.AdditionalFields["requestId"]
+	if !exists {
+		return
+	}
+
+	key := requestKey(ctx, requestID(id))
+
+	r.mu.Lock()
+	cancel, exists := r.cancels[key]
+	r.mu.Unlock()
+
+	// The request may have completed already
+	if !exists {
+		return
+	}
+
+	log.Printf("Client cancelled request %v: %v", id, notification.Params.AdditionalFields["reason"])
+
+	cancel(ErrRequestCancelled)
+}
+
+// cancellable wraps a handler so that its context is canceled when the
+// client cancels the request.
+func cancellable[Request, Result any](
+	tracker *requests,
+	handler func(ctx context.Context, request Request) (Result, error),
+	header func(request Request) http.Header,
+) func(ctx context.Context, request Request) (Result, error) {
+	return func(ctx context.Context, request Request) (Result, error) {
+		ctx, done := tracker.track(ctx, header(request))
+		defer done()
+
+		return handler(ctx, request)
+	}
+}
+
+// addCancellation makes the requests of the server cancellable.
+func (r *requests) addCancellation(mcpServer *server.MCPServer) {
+	mcpServer.AddNotificationHandler(isolate.MethodNotificationCancelled, r.cancelled)
+}
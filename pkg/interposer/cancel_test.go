@@ -0,0 +1,129 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package interposer
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// blockingClient is a mock client whose tool calls only return once canceled.
+type blockingClient struct {
+	*MockMCPClient
+
+	called chan http.Header
+	causes chan error
+}
+
+// newBlockingClient creates a new blocking client.
+func newBlockingClient() *blockingClient {
+	return &blockingClient{
+		MockMCPClient: createMockClient(),
+		called:        make(chan http.Header, 1),
+		causes:        make(chan error, 1),
+	}
+}
+
+// CallTool implements the CallTool method of the MCPClient interface.
+func (c *blockingClient) CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	c.called <- request.Header
+
+	<-ctx.Done()
+
+	c.causes <- context.Cause(ctx)
+
+	return nil, ctx.Err()
+}
+
+func TestCancelledRequests(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer("TestInterposer", "1.0.0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	mcpClient := newBlockingClient()
+	tool := mcp.NewTool("slow")
+	cfg := config.Server{Name: "backend"}
+
+	require.NoError(t, interposerInstance.RegisterTool(
+		"backend",
+		tool,
+		handleTool(cfg, tool, mcpClient, func() bool { return true }),
+	))
+
+	ctx := context.Background()
+	mcpServer := interposerInstance.Server()
+
+	done := make(chan mcp.JSONRPCMessage, 1)
+
+	go func() {
+		done <- mcpServer.HandleMessage(ctx, []byte(
+			`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"slow"}}`,
+		))
+	}()
+
+	select {
+	case header := <-mcpClient.called:
+		assert.Empty(t, header.Get(requestIDHeader), "the backend must not see the request tag")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the tool was not called")
+	}
+
+	// Cancelling another request leaves the call running
+	mcpServer.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":8}}`,
+	))
+
+	select {
+	case <-mcpClient.causes:
+		t.Fatal("the call was cancelled by another request")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	mcpServer.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"user abort"}}`,
+	))
+
+	select {
+	case cause := <-mcpClient.causes:
+		require.ErrorIs(t, cause, ErrRequestCancelled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the call was not cancelled")
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the cancelled call did not return")
+	}
+
+	interposerInstance.requests.mu.Lock()
+	assert.Empty(t, interposerInstance.requests.cancels, "finished requests must be untracked")
+	interposerInstance.requests.mu.Unlock()
+}
+
+func TestCallTimeout(t *testing.T) {
+	t.Parallel()
+
+	mcpClient := newBlockingClient()
+	tool := mcp.NewTool("slow")
+	cfg := config.Server{
+		Name:     "backend",
+		Timeouts: config.Timeouts{Call: config.Duration(20 * time.Millisecond)},
+	}
+
+	handler := handleTool(cfg, tool, mcpClient, func() bool { return true })
+
+	_, err := handler(context.Background(), mcp.CallToolRequest{})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorIs(t, <-mcpClient.causes, context.DeadlineExceeded)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -231,3 +232,108 @@ func TestCapabilityFiltering(t *testing.T) {
 		assert.True(t, mockMcp.listResourceTemplatesCalled, "templates should be enabled")
 	})
 }
+
+func TestCapabilityPatternFiltering(t *testing.T) {
+	t.Parallel()
+
+	mockClient := createMockClient()
+	mockClient.tools = []mcp.Tool{
+		mcp.NewTool("browser_navigate"),
+		mcp.NewTool("browser_close"),
+		mcp.NewTool("github_create_issue"),
+		mcp.NewTool("github_create_pull_request"),
+	}
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(func(_ config.Server) (client.MCPClient, error) { return mockClient, nil }),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	require.NoError(t, interposerInstance.AddBackend(context.Background(), "test-server", config.Server{
+		Name: "test-server",
+		Type: config.ServerTypeStdio,
+		Enable: &config.Capability{
+			Capabilities: map[config.CapabilityType][]string{
+				config.CapabilityTypeTool: {"browser_*", "github_*_issue"},
+			},
+		},
+		Disable: &config.Capability{
+			Capabilities: map[config.CapabilityType][]string{
+				config.CapabilityTypeTool: {"re:.*_close"},
+			},
+		},
+	}))
+
+	var names []string
+
+	for _, tool := range interposerInstance.Server().ListTools() {
+		names = append(names, tool.Tool.Name)
+	}
+
+	assert.ElementsMatch(t, []string{"test-server-browser_navigate", "test-server-github_create_issue"}, names)
+}
+
+func TestCapabilityAnnotationFiltering(t *testing.T) {
+	t.Parallel()
+
+	factory := func(_ config.Server) (client.MCPClient, error) {
+		mockClient := createMockClient()
+		mockClient.tools = []mcp.Tool{
+			mcp.NewTool("read_file", mcp.WithReadOnlyHintAnnotation(true)),
+			mcp.NewTool("write_file", mcp.WithDestructiveHintAnnotation(false)),
+			mcp.NewTool("delete_file"),
+			{Name: "unannotated", InputSchema: mcp.ToolInputSchema{Type: "object"}},
+		}
+
+		return mockClient, nil
+	}
+
+	tests := []struct {
+		name   string
+		config config.Server
+		want   []string
+	}{
+		{
+			name: "read-only mode",
+			config: config.Server{
+				Enable: &config.Capability{Annotations: map[string]bool{config.AnnotationReadOnly: true}},
+			},
+			want: []string{"test-server-read_file"},
+		},
+		{
+			name: "no destructive tools",
+			config: config.Server{
+				Disable: &config.Capability{Annotations: map[string]bool{config.AnnotationDestructive: true}},
+			},
+			want: []string{"test-server-read_file", "test-server-write_file"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			interposerInstance, err := NewInterposer("TestInterposer", "1.0.0", WithClientFactory(factory))
+			require.NoError(t, err)
+
+			t.Cleanup(func() { _ = interposerInstance.Close() })
+
+			serverConfig := test.config
+			serverConfig.Name = "test-server"
+
+			require.NoError(t, interposerInstance.AddBackend(context.Background(), "test-server", serverConfig))
+
+			var names []string
+
+			for _, tool := range interposerInstance.Server().ListTools() {
+				names = append(names, tool.Tool.Name)
+			}
+
+			assert.ElementsMatch(t, test.want, names)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package interposer
+
+import (
+	"sync"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// catalog keeps the items registered with the server, so that they can be
+// compared when a backend changes them and removed by name.
+type catalog struct {
+	// Maps capability to its server.ServerTool, ServerPrompt, ServerResource
+	// or ServerResourceTemplate
+	items map[CapabilityKey]any
+
+	mu sync.RWMutex
+}
+
+// newCatalog creates a new catalog.
+func newCatalog() *catalog {
+	return &catalog{
+		items: make(map[CapabilityKey]any),
+	}
+}
+
+// add records an item registered with the server.
+func (c *catalog) add(capType, capName string, item any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[CapabilityKey{Type: capType, Name: capName}] = item
+}
+
+// get returns a registered item.
+func (c *catalog) get(capType, capName string) (any, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[CapabilityKey{Type: capType, Name: capName}]
+
+	return item, exists
+}
+
+// remove forgets a registered item, returning it.
+func (c *catalog) remove(capType, capName string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := CapabilityKey{Type: capType, Name: capName}
+
+	item, exists := c.items[key]
+	delete(c.items, key)
+
+	return item, exists
+}
+
+// findURI returns the name of the registered resource or resource template
+// with the URI or URI template.
+func (c *catalog) findURI(capType, uri string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for key, item := range c.items {
+		if key.Type != capType {
+			continue
+		}
+
+		switch value := item.(type) {
+		case server.ServerResource:
+			if value.Resource.URI == uri {
+				return key.Name, true
+			}
+		case server.ServerResourceTemplate:
+			if value.Template.URITemplate.Raw() == uri {
+				return key.Name, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// addTemplate registers a resource template with the server and records it.
+// Templates can only be removed from the server by replacing them as a whole,
+// so both happen under the lock to not lose a template being added.
+func (c *catalog) addTemplate(mcpServer *server.MCPServer, template server.ServerResourceTemplate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	mcpServer.AddResourceTemplate(template.Template, template.Handler)
+	c.items[CapabilityKey{Type: "template", Name: template.Template.Name}] = template
+}
+
+// removeTemplates forgets registered resource templates, replacing the
+// templates of the server with the remaining ones.
+func (c *catalog) removeTemplates(mcpServer *server.MCPServer, names []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, name := range names {
+		delete(c.items, CapabilityKey{Type: "template", Name: name})
+	}
+
+	var templates []server.ServerResourceTemplate
+
+	for key, item := range c.items {
+		if template, ok := item.(server.ServerResourceTemplate); ok && key.Type == "template" {
+			templates = append(templates, template)
+		}
+	}
+
+	mcpServer.SetResourceTemplates(templates...)
+}
+
+// deleteItems removes registered items of a type from the server.
+func (i *Interposer) deleteItems(capType string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	if capType == "template" {
+		i.catalog.removeTemplates(i.server, names)
+
+		return
+	}
+
+	var uris []string
+
+	for _, name := range names {
+		item, exists := i.catalog.remove(capType, name)
+		if resource, ok := item.(server.ServerResource); exists && ok {
+			uris = append(uris, resource.Resource.URI)
+		}
+	}
+
+	switch capType {
+	case "tool":
+		i.server.DeleteTools(names...)
+	case "prompt":
+		i.server.DeletePrompts(names...)
+	case "resource":
+		i.server.DeleteResources(uris...)
+	}
+}
@@ -12,6 +12,40 @@ import (
 // ErrNoInitializationResult is returned when the initialization result is nil.
 var ErrNoInitializationResult = errors.New("initialization result is nil")
 
+// starter is implemented by clients whose transport must be started before use.
+type starter interface {
+	Start(ctx context.Context) error
+}
+
+// Start starts the transport of an MCP client, if it needs starting. The
+// ctx bounds connecting, while the connection lives as long as lifetime.
+func Start(ctx, lifetime context.Context, mcpClient client.MCPClient) error {
+	startable, ok := mcpClient.(starter)
+	if !ok {
+		return nil
+	}
+
+	// Closing the client aborts connecting once ctx is done
+	stop := context.AfterFunc(ctx, func() {
+		_ = mcpClient.Close()
+	})
+	defer stop()
+
+	if err := startable.Start(lifetime); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("failed to start client: %w", ctx.Err())
+		}
+
+		return fmt.Errorf("failed to start client: %w", err)
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("failed to start client: %w", ctx.Err())
+	}
+
+	return nil
+}
+
 // Initialize initializes an MCP client.
 func Initialize(
 	ctx context.Context,
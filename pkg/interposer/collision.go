@@ -0,0 +1,300 @@
+package interposer
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+// ErrCapabilityCollision is returned when a capability is already registered by another backend.
+var ErrCapabilityCollision = errors.New("capability already registered by another backend")
+
+// ErrUnknownCollisionPolicy is returned for a collision policy that does not exist.
+var ErrUnknownCollisionPolicy = errors.New("unknown collision policy")
+
+// CollisionPolicy decides what happens when two backends expose a capability
+// under the same name or URI.
+type CollisionPolicy string
+
+const (
+	// CollisionPolicyError keeps the capability registered first and fails
+	// registering the other one with ErrCapabilityCollision.
+	CollisionPolicyError CollisionPolicy = "error"
+
+	// CollisionPolicyFirstWins keeps the capability registered first and
+	// skips the other one.
+	CollisionPolicyFirstWins CollisionPolicy = "first-wins"
+
+	// CollisionPolicyLastWins replaces the capability registered first with
+	// the other one.
+	CollisionPolicyLastWins CollisionPolicy = "last-wins"
+
+	// CollisionPolicyAutoSuffix registers the other capability under its
+	// name with a numeric suffix, such as `github-search-2`. Capabilities
+	// sharing a URI can not be suffixed and fail as with CollisionPolicyError.
+	CollisionPolicyAutoSuffix CollisionPolicy = "auto-suffix"
+)
+
+// ParseCollisionPolicy returns the collision policy of the name, the error
+// policy if it is empty.
+func ParseCollisionPolicy(name string) (CollisionPolicy, error) {
+	switch policy := CollisionPolicy(name); policy {
+	case "":
+		return CollisionPolicyError, nil
+	case CollisionPolicyError, CollisionPolicyFirstWins, CollisionPolicyLastWins, CollisionPolicyAutoSuffix:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownCollisionPolicy, name)
+	}
+}
+
+// Collision records a capability that a backend exposes under a name or URI
+// another backend registered first.
+type Collision struct {
+	// Type is the type of the capability
+	Type string
+
+	// Name is the name the backend exposes the capability under
+	Name string
+
+	// Owner is the backend that registered the name or URI first
+	Owner string
+
+	// Backend is the backend whose capability collided
+	Backend string
+
+	// Policy is the policy the collision was resolved with
+	Policy CollisionPolicy
+
+	// Registered is the name the backend's capability was registered under,
+	// empty if it was not registered
+	Registered string
+}
+
+// String returns a description of the collision.
+func (c Collision) String() string {
+	var resolution string
+
+	switch {
+	case c.Registered == "":
+		resolution = "kept " + c.Owner + "'s"
+	case c.Registered == c.Name:
+		resolution = "replaced " + c.Owner + "'s"
+	default:
+		resolution = "registered " + c.Backend + "'s as " + c.Registered
+	}
+
+	return fmt.Sprintf(
+		"%s %s from %s collides with %s, %s %s",
+		c.Type, c.Name, c.Backend, c.Owner, c.Policy, resolution,
+	)
+}
+
+// SetCollisionPolicy sets the policy resolving collisions of capabilities
+// registered from now on.
+func (r *CapabilityRegistry) SetCollisionPolicy(policy CollisionPolicy) error {
+	policy, err := ParseCollisionPolicy(string(policy))
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.policy = policy
+
+	return nil
+}
+
+// GetCollisionPolicy returns the policy resolving collisions.
+func (r *CapabilityRegistry) GetCollisionPolicy() CollisionPolicy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.policy
+}
+
+// GetCollisions returns the collisions between the registered backends.
+func (r *CapabilityRegistry) GetCollisions() []Collision {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	collisions := make([]Collision, len(r.collisions))
+	copy(collisions, r.collisions)
+
+	return collisions
+}
+
+// GetCollisionsForBackend returns the collisions a backend is involved in,
+// either as the owner or as the backend whose capability collided.
+func (r *CapabilityRegistry) GetCollisionsForBackend(backend string) []Collision {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var collisions []Collision
+
+	for _, collision := range r.collisions {
+		if collision.Owner == backend || collision.Backend == backend {
+			collisions = append(collisions, collision)
+		}
+	}
+
+	return collisions
+}
+
+// GetRequestedName returns the name a backend exposes a capability under,
+// which differs from the registered name if it was suffixed.
+func (r *CapabilityRegistry) GetRequestedName(capType, capName string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if requested, exists := r.requested[CapabilityKey{Type: capType, Name: capName}]; exists {
+		return requested
+	}
+
+	return capName
+}
+
+// Provides returns true if the backend registered a capability under the
+// name, or under the name with a suffix.
+func (r *CapabilityRegistry) Provides(backend, capType, capName string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for key := range r.backendCaps[backend] {
+		if key.Type == capType && (key.Name == capName || r.requested[key] == capName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Claim checks whether a backend may register a capability under its name,
+// resolving a collision with another backend according to the policy. For
+// resources and templates, sharing is the name of the capability registered
+// with the same URI, if any.
+//
+// Returns the name to register the capability under, empty to skip it, and
+// the capabilities of other backends that it replaces, which are no longer
+// registered and have to be removed from the server.
+func (r *CapabilityRegistry) Claim(backend, capType, capName, sharing string) (string, []string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var (
+		owner     string
+		conflicts []string
+		sharedURI bool
+	)
+
+	if current, exists := r.capabilities[CapabilityKey{Type: capType, Name: capName}]; exists && current != backend {
+		owner = current
+		conflicts = append(conflicts, capName)
+	}
+
+	if sharing != "" && sharing != capName {
+		if current, exists := r.capabilities[CapabilityKey{Type: capType, Name: sharing}]; exists && current != backend {
+			owner = current
+			sharedURI = true
+			conflicts = append(conflicts, sharing)
+		}
+	}
+
+	if owner == "" {
+		return capName, nil, nil
+	}
+
+	collision := Collision{
+		Type:    capType,
+		Name:    capName,
+		Owner:   owner,
+		Backend: backend,
+		Policy:  r.policy,
+	}
+
+	var (
+		displaced []string
+		err       error
+	)
+
+	switch {
+	case r.policy == CollisionPolicyFirstWins:
+		// The capability registered first is kept
+
+	case r.policy == CollisionPolicyLastWins:
+		for _, conflict := range conflicts {
+			r.removeCapability(CapabilityKey{Type: capType, Name: conflict})
+		}
+
+		collision.Registered = capName
+		displaced = conflicts
+
+	case r.policy == CollisionPolicyAutoSuffix && !sharedURI:
+		collision.Registered = r.suffixed(backend, capType, capName)
+
+	default:
+		err = fmt.Errorf(
+			"%w: %s %s from %s is already registered by %s",
+			ErrCapabilityCollision, capType, capName, backend, owner,
+		)
+	}
+
+	r.recordCollision(collision)
+
+	return collision.Registered, displaced, err
+}
+
+// suffixed returns the name with the lowest numeric suffix that is free or
+// already registered by the backend for the same name, and remembers it.
+func (r *CapabilityRegistry) suffixed(backend, capType, capName string) string {
+	for suffix := 2; ; suffix++ {
+		key := CapabilityKey{Type: capType, Name: capName + "-" + strconv.Itoa(suffix)}
+
+		current, exists := r.capabilities[key]
+		if exists && (current != backend || r.requested[key] != capName) {
+			continue
+		}
+
+		r.requested[key] = capName
+
+		return key.Name
+	}
+}
+
+// recordCollision records a collision, logging it unless it is already known.
+func (r *CapabilityRegistry) recordCollision(collision Collision) {
+	for idx, known := range r.collisions {
+		if known.Type == collision.Type && known.Name == collision.Name && known.Backend == collision.Backend {
+			r.collisions[idx] = collision
+
+			return
+		}
+	}
+
+	r.collisions = append(r.collisions, collision)
+
+	log.Printf("Warning: %s", collision)
+}
+
+// forgetCollisions forgets the collisions a capability of a backend is
+// involved in, as the owner or the backend whose capability collided.
+func (r *CapabilityRegistry) forgetCollisions(backend string, key CapabilityKey) {
+	requested := key.Name
+	if name, exists := r.requested[key]; exists {
+		requested = name
+	}
+
+	collisions := r.collisions[:0]
+
+	for _, collision := range r.collisions {
+		involved := collision.Type == key.Type && (collision.Owner == backend && collision.Name == key.Name ||
+			collision.Backend == backend && collision.Name == requested)
+		if !involved {
+			collisions = append(collisions, collision)
+		}
+	}
+
+	r.collisions = collisions
+}
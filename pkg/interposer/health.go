@@ -0,0 +1,119 @@
+package interposer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// ErrBackendUnhealthy is returned for requests to a backend failing its health checks.
+var ErrBackendUnhealthy = errors.New("backend is unhealthy")
+
+// Health represents the health of a backend.
+type Health string
+
+const (
+	// HealthUnknown is the health of a backend that is not connected.
+	HealthUnknown Health = "unknown"
+
+	// HealthHealthy is the health of a connected backend passing its health checks.
+	HealthHealthy Health = "healthy"
+
+	// HealthUnhealthy is the health of a connected backend failing its health checks.
+	HealthUnhealthy Health = "unhealthy"
+)
+
+// checkHealth returns an error if the backend is unhealthy.
+func (i *Interposer) checkHealth(name string) error {
+	if i.registry.GetBackendHealth(name) == HealthUnhealthy {
+		return fmt.Errorf("%w: %s is failing its health checks", ErrBackendUnhealthy, name)
+	}
+
+	return nil
+}
+
+// isCurrentClient returns true if the client is still the one serving the backend.
+func (i *Interposer) isCurrentClient(name string, mcpClient client.MCPClient) bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	current, exists := i.clients[name]
+
+	return exists && current == mcpClient
+}
+
+// isCurrentHealthCheck returns true if the client still serves the backend
+// and the backend is still configured with the health check.
+func (i *Interposer) isCurrentHealthCheck(name string, mcpClient client.MCPClient, check config.HealthCheck) bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	current, exists := i.clients[name]
+	cfg := i.configs[name]
+
+	return exists && current == mcpClient && cfg.HealthCheck.IsEnabled() && cfg.HealthCheck.WithDefaults() == check
+}
+
+// sameHealthCheck returns true if both configurations check health the same way.
+func sameHealthCheck(first, second *config.HealthCheck) bool {
+	if !first.IsEnabled() || !second.IsEnabled() {
+		return first.IsEnabled() == second.IsEnabled()
+	}
+
+	return first.WithDefaults() == second.WithDefaults()
+}
+
+// monitorHealth pings a backend periodically, marking it unhealthy after the
+// configured number of consecutive failures and healthy again once a ping
+// succeeds. Stops once the client is replaced, its health check changes or
+// the interposer is closed.
+func (i *Interposer) monitorHealth(name string, mcpClient client.MCPClient, check config.HealthCheck) {
+	ticker := time.NewTicker(check.Interval.Duration())
+	defer ticker.Stop()
+
+	failures := 0
+
+	for {
+		select {
+		case <-i.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !i.isCurrentHealthCheck(name, mcpClient, check) {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(i.ctx, check.Timeout.Duration())
+		err := mcpClient.Ping(ctx)
+
+		cancel()
+
+		if err == nil {
+			if failures >= check.Threshold {
+				log.Printf("Backend %s recovered", name)
+			}
+
+			failures = 0
+
+			i.registry.SetBackendHealth(name, HealthHealthy)
+
+			continue
+		}
+
+		failures++
+
+		log.Printf("Health check of backend %s failed (%d/%d): %v", name, failures, check.Threshold, err)
+
+		if failures == check.Threshold {
+			log.Printf("Backend %s is unhealthy", name)
+			i.registry.SetBackendHealth(name, HealthUnhealthy)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package interposer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+var errPingFailed = errors.New("ping failed")
+
+// sickMCPClient is a mock client whose pings fail while it is sick.
+type sickMCPClient struct {
+	*MockMCPClient
+
+	sick atomic.Bool
+}
+
+// Ping implements the Ping method of the MCPClient interface.
+func (m *sickMCPClient) Ping(_ context.Context) error {
+	if m.sick.Load() {
+		return errPingFailed
+	}
+
+	return nil
+}
+
+func TestHealthCheckShortCircuitsUnhealthyBackend(t *testing.T) {
+	t.Parallel()
+
+	mockClient := &sickMCPClient{MockMCPClient: createMockClient()}
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(func(_ config.Server) (client.MCPClient, error) {
+			return mockClient, nil
+		}),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	serverConfig := config.Server{
+		Name: "test-server",
+		Type: config.ServerTypeStdio,
+		HealthCheck: &config.HealthCheck{
+			Interval:  config.Duration(time.Millisecond),
+			Threshold: 2,
+		},
+	}
+
+	require.NoError(t, interposerInstance.AddBackend(context.Background(), "test-server", serverConfig))
+	assert.Equal(t, HealthHealthy, interposerInstance.registry.GetBackendHealth("test-server"))
+
+	// Failing health checks mark the backend unhealthy and calls fail fast
+	mockClient.sick.Store(true)
+
+	require.Eventually(t, func() bool {
+		return interposerInstance.registry.GetBackendHealth("test-server") == HealthUnhealthy
+	}, time.Second, time.Millisecond)
+
+	require.ErrorIs(t, callTool(t, interposerInstance, "test-server-test-tool"), ErrBackendUnhealthy)
+
+	// The backend keeps its capabilities and recovers once pings succeed
+	mockClient.sick.Store(false)
+
+	require.Eventually(t, func() bool {
+		return interposerInstance.registry.GetBackendHealth("test-server") == HealthHealthy
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, callTool(t, interposerInstance, "test-server-test-tool"))
+}
+
+func TestHealthCheckForgetsRemovedBackend(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(func(_ config.Server) (client.MCPClient, error) {
+			return createMockClient(), nil
+		}),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	ctx := context.Background()
+
+	require.NoError(t, interposerInstance.AddBackend(ctx, "test-server", config.Server{
+		Name:        "test-server",
+		Type:        config.ServerTypeStdio,
+		HealthCheck: &config.HealthCheck{},
+	}))
+
+	interposerInstance.removeBackend(ctx, "test-server")
+
+	assert.Equal(t, HealthUnknown, interposerInstance.registry.GetBackendHealth("test-server"))
+	assert.Empty(t, interposerInstance.registry.GetAllBackendHealth())
+}
+
+func TestHealthCheckDefaults(t *testing.T) {
+	t.Parallel()
+
+	defaults := (*config.HealthCheck)(nil).WithDefaults()
+	assert.Equal(t, config.Duration(config.DefaultHealthCheckInterval), defaults.Interval)
+	assert.Equal(t, config.Duration(config.DefaultHealthCheckTimeout), defaults.Timeout)
+	assert.Equal(t, config.DefaultHealthCheckThreshold, defaults.Threshold)
+}
@@ -5,14 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"slices"
 	"sync"
+	"sync/atomic"
 
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/jkoelker/posuer/pkg/config"
-	"github.com/jkoelker/posuer/pkg/isolate"
+	"github.com/jkoelker/posuer/pkg/manifest"
 )
 
 // ErrBackendNotFound is returned when a backend is not found.
@@ -24,9 +27,38 @@ type Interposer struct {
 	version  string
 	server   *server.MCPServer
 	clients  map[string]client.MCPClient
-	mu       sync.RWMutex // protects clients
+	configs  map[string]config.Server
+	results  map[string]*mcp.InitializeResult
+	mu       sync.RWMutex // protects clients, configs and results
 	registry *CapabilityRegistry
+	catalog  *catalog
+	notifier *notifier
+	requests *requests
+	progress *progress
+	callers  *callers
+	roots    *roots
 	factory  func(config.Server) (client.MCPClient, error)
+
+	// registering serializes claiming, adding and recording capabilities, so
+	// that backends starting concurrently cannot both claim a name or URI
+	registering sync.Mutex
+
+	// subscriptions tracks the resources clients are subscribed to
+	subscriptions *subscriptions
+
+	// manifests caches what lazy backends advertise, nil to start them right away
+	manifests *manifest.Store
+
+	// safeMode only exposes and allows calling safe tools, see SetSafeMode
+	safeMode atomic.Bool
+
+	// ctx bounds background work such as reconnects, canceled on Close
+	ctx    context.Context //nolint:containedctx // Lifetime of the interposer
+	cancel context.CancelFunc
+
+	lifecycle    sync.Mutex // serializes reconfiguring and reconnecting backends
+	reconnects   map[string]*pendingReconnect
+	reconnectsMu sync.Mutex // protects reconnects
 }
 
 // WithClientFactory sets a custom client factory for creating MCP clients.
@@ -38,27 +70,95 @@ func WithClientFactory(factory func(config.Server) (client.MCPClient, error)) fu
 	}
 }
 
+// WithManifestStore sets the store caching the manifests of lazy backends.
+func WithManifestStore(store *manifest.Store) func(*Interposer) error {
+	return func(i *Interposer) error {
+		i.manifests = store
+
+		return nil
+	}
+}
+
+// WithSafeMode starts the interposer in safe mode, see SetSafeMode.
+func WithSafeMode(enabled bool) func(*Interposer) error {
+	return func(i *Interposer) error {
+		i.safeMode.Store(enabled)
+
+		return nil
+	}
+}
+
+// WithCollisionPolicy sets the policy resolving capabilities that backends
+// expose under the same name, see CollisionPolicy.
+func WithCollisionPolicy(policy CollisionPolicy) func(*Interposer) error {
+	return func(i *Interposer) error {
+		return i.registry.SetCollisionPolicy(policy)
+	}
+}
+
 // NewInterposer creates a new MCP interposer.
 func NewInterposer(name, version string, opts ...func(*Interposer) error) (*Interposer, error) {
+	// Requests forwarded to backends may be cancelled by the client
+	tracker := newRequests()
+
+	// Roots requests of backends are relayed to the clients declaring roots
+	sessions := newRoots()
+
+	hooks := notificationHooks()
+	tracker.hooks(hooks)
+	sessions.hooks(hooks)
+
+	// List changes are notified by the interposer, see advertiseListChanged
 	mcpServer := server.NewMCPServer(
 		name,
 		version,
-		server.WithResourceCapabilities(true, true),
-		server.WithPromptCapabilities(true),
-		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(true, false),
+		server.WithPromptCapabilities(false),
+		server.WithToolCapabilities(false),
+		server.WithHooks(hooks),
 	)
 
+	tracker.addCancellation(mcpServer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var manifests *manifest.Store
+	if dir, err := manifest.DefaultDir(); err == nil {
+		manifests = manifest.NewStore(dir)
+	}
+
 	interposer := &Interposer{
-		name:     name,
-		version:  version,
-		server:   mcpServer,
-		clients:  make(map[string]client.MCPClient),
-		registry: NewCapabilityRegistry(),
-		factory:  isolate.Client,
+		name:       name,
+		version:    version,
+		server:     mcpServer,
+		clients:    make(map[string]client.MCPClient),
+		configs:    make(map[string]config.Server),
+		results:    make(map[string]*mcp.InitializeResult),
+		registry:   NewCapabilityRegistry(),
+		catalog:    newCatalog(),
+		requests:   tracker,
+		progress:   newProgress(),
+		callers:    newCallers(),
+		roots:      sessions,
+		manifests:  manifests,
+		ctx:        ctx,
+		cancel:     cancel,
+		reconnects: make(map[string]*pendingReconnect),
 	}
 
+	interposer.notifier = newNotifier(interposer.notifyAll)
+	interposer.factory = interposer.newClient
+
+	interposer.forwardRoots()
+
+	// Subscriptions of a client end with its session
+	interposer.subscriptions = newSubscriptions(interposer.releaseSubscriptions)
+	interposer.subscriptions.hooks(hooks)
+
 	for _, opt := range opts {
 		if err := opt(interposer); err != nil {
+			cancel()
+
 			return nil, fmt.Errorf("failed to apply option: %w", err)
 		}
 	}
@@ -71,6 +171,11 @@ func (i *Interposer) Server() *server.MCPServer {
 	return i.server
 }
 
+// Registry returns the registry tracking which backend provides each capability.
+func (i *Interposer) Registry() *CapabilityRegistry {
+	return i.registry
+}
+
 // ImplementationInfo returns the implementation information of the interposer.
 func (i *Interposer) ImplementationInfo() mcp.Implementation {
 	return mcp.Implementation{
@@ -85,66 +190,226 @@ func (i *Interposer) AddBackend(
 	name string,
 	cfg config.Server,
 ) error {
-	mcpClient, err := i.factory(cfg)
-	if err != nil {
-		return fmt.Errorf("failed to create MCP client: %w", err)
+	var (
+		mcpClient client.MCPClient
+		result    *mcp.InitializeResult
+		err       error
+	)
+
+	if cfg.Lazy || cfg.IdleTimeout > 0 {
+		mcpClient, result, err = i.connectLazy(ctx, name, cfg)
+	} else {
+		mcpClient, result, err = i.connect(ctx, name, cfg)
 	}
 
-	// Initialize the client
-	result, err := Initialize(ctx, mcpClient, i.ImplementationInfo(), name)
 	if err != nil {
-		return fmt.Errorf("failed to initialize MCP client: %w", err)
+		return err
 	}
 
+	// Detect the backend dying so it can be reconnected
+	mcpClient = i.supervise(name, mcpClient)
+
 	// Register client's capabilities with our server
 	i.addClientCapabilities(ctx, mcpClient, result, cfg)
 
-	// Store the client
+	// Store the client, the config it was created from and what it
+	// advertised, as backends are only initialized once
 	i.mu.Lock()
 	i.clients[name] = mcpClient
+	i.configs[name] = cfg
+	i.results[name] = result
 	i.mu.Unlock()
 
+	// Keep the capabilities current when the backend changes them
+	i.watchListChanges(name, mcpClient)
+
+	// Relay the progress of tool calls to the clients that made them
+	i.watchProgress(name, mcpClient)
+
+	// Relay resource updates to the clients subscribed to them, which a
+	// restarted backend has to be subscribed to again
+	i.watchSubscriptions(name, mcpClient)
+	i.resubscribe(ctx, name, mcpClient)
+
+	// A freshly initialized backend is healthy until its checks say otherwise
+	i.registry.SetBackendHealth(name, HealthHealthy)
+
+	if cfg.HealthCheck.IsEnabled() {
+		go i.monitorHealth(name, mcpClient, cfg.HealthCheck.WithDefaults())
+	}
+
 	return nil
 }
 
-// RegisterTool registers a tool and tracks its source.
+// connect creates, starts and initializes the client of a backend.
+func (i *Interposer) connect(
+	ctx context.Context,
+	name string,
+	cfg config.Server,
+) (client.MCPClient, *mcp.InitializeResult, error) {
+	mcpClient, err := i.factory(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create MCP client: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, cfg.Timeouts.Initialize)
+	defer cancel()
+
+	// Network transports only connect once started. The connection lives as
+	// long as the interposer, ctx only bounds connecting and initializing.
+	if err := Start(ctx, i.ctx, mcpClient); err != nil {
+		closeClient(name, mcpClient)
+
+		return nil, nil, fmt.Errorf("failed to start MCP client: %w", err)
+	}
+
+	// Initialize the client
+	result, err := Initialize(ctx, mcpClient, i.ImplementationInfo(), name)
+	if err != nil {
+		closeClient(name, mcpClient)
+
+		return nil, nil, fmt.Errorf("failed to initialize MCP client: %w", err)
+	}
+
+	return mcpClient, result, nil
+}
+
+// RegisterTool registers a tool and tracks its source. Calls of the tool may
+// be cancelled by the client, and the progress the backend reports on them
+// and its sampling requests are relayed to the client.
+// A tool of the same name registered by another backend is resolved according
+// to the collision policy, see CapabilityRegistry.Claim.
 func (i *Interposer) RegisterTool(
 	backendName string,
 	tool mcp.Tool,
 	handler server.ToolHandlerFunc,
-) {
+) error {
+	i.registering.Lock()
+	defer i.registering.Unlock()
+
+	capName, err := i.claim(backendName, "tool", tool.Name, "")
+	if capName == "" {
+		return err
+	}
+
+	tool.Name = capName
+	handler = reportingProgress(i.progress, fromCaller(i.callers, backendName, handler))
+	handler = cancellable(i.requests, handler, func(request mcp.CallToolRequest) http.Header {
+		return request.Header
+	})
+
 	i.server.AddTool(tool, handler)
 	i.registry.AddCapability(backendName, "tool", tool.Name)
+	i.catalog.add("tool", tool.Name, server.ServerTool{Tool: tool, Handler: handler})
+
+	return nil
 }
 
 // RegisterPrompt registers a prompt and tracks its source.
+// A prompt of the same name registered by another backend is resolved
+// according to the collision policy, see CapabilityRegistry.Claim.
 func (i *Interposer) RegisterPrompt(
 	backendName string,
 	prompt mcp.Prompt,
 	handler server.PromptHandlerFunc,
-) {
+) error {
+	i.registering.Lock()
+	defer i.registering.Unlock()
+
+	capName, err := i.claim(backendName, "prompt", prompt.Name, "")
+	if capName == "" {
+		return err
+	}
+
+	prompt.Name = capName
+	handler = cancellable(i.requests, handler, func(request mcp.GetPromptRequest) http.Header {
+		return request.Header
+	})
+
 	i.server.AddPrompt(prompt, handler)
 	i.registry.AddCapability(backendName, "prompt", prompt.Name)
+	i.catalog.add("prompt", prompt.Name, server.ServerPrompt{Prompt: prompt, Handler: handler})
+
+	return nil
 }
 
 // RegisterResource registers a resource and tracks its source.
+// A resource of the same name or URI registered by another backend is
+// resolved according to the collision policy, see CapabilityRegistry.Claim.
 func (i *Interposer) RegisterResource(
 	backendName string,
 	resource mcp.Resource,
 	handler server.ResourceHandlerFunc,
-) {
+) error {
+	i.registering.Lock()
+	defer i.registering.Unlock()
+
+	capName, err := i.claim(backendName, "resource", resource.Name, resource.URI)
+	if capName == "" {
+		return err
+	}
+
+	resource.Name = capName
+	handler = cancellable(i.requests, handler, func(request mcp.ReadResourceRequest) http.Header {
+		return request.Header
+	})
+
 	i.server.AddResource(resource, handler)
 	i.registry.AddCapability(backendName, "resource", resource.Name)
+	i.catalog.add("resource", resource.Name, server.ServerResource{Resource: resource, Handler: handler})
+
+	return nil
 }
 
 // RegisterResourceTemplate registers a resource template and tracks its source.
+// A resource template of the same name or URI template registered by another
+// backend is resolved according to the collision policy, see
+// CapabilityRegistry.Claim.
 func (i *Interposer) RegisterResourceTemplate(
 	backendName string,
 	template mcp.ResourceTemplate,
 	handler server.ResourceTemplateHandlerFunc,
-) {
-	i.server.AddResourceTemplate(template, handler)
+) error {
+	i.registering.Lock()
+	defer i.registering.Unlock()
+
+	capName, err := i.claim(backendName, "template", template.Name, template.URITemplate.Raw())
+	if capName == "" {
+		return err
+	}
+
+	template.Name = capName
+	handler = cancellable(i.requests, handler, func(request mcp.ReadResourceRequest) http.Header {
+		return request.Header
+	})
+
+	i.catalog.addTemplate(i.server, server.ServerResourceTemplate{Template: template, Handler: handler})
 	i.registry.AddCapability(backendName, "template", template.Name)
+
+	return nil
+}
+
+// claim returns the name to register a capability under, empty if it must not
+// be registered, resolving collisions with the capabilities of other backends
+// of the same name or, for resources and templates, the same URI. Capabilities
+// the policy replaces are removed from the server.
+func (i *Interposer) claim(backendName, capType, capName, uri string) (string, error) {
+	var sharing string
+
+	if uri != "" {
+		sharing, _ = i.catalog.findURI(capType, uri)
+	}
+
+	registered, displaced, err := i.registry.Claim(backendName, capType, capName, sharing)
+	if err != nil {
+		return "", err
+	}
+
+	// Resources are keyed by URI on the server, so a replaced resource is
+	// not overwritten when it has a different name
+	i.deleteItems(capType, displaced)
+
+	return registered, nil
 }
 
 // RemoveTrackedCapabilities removes all capabilities that came from a specific backend
@@ -157,38 +422,27 @@ func (i *Interposer) RemoveTrackedCapabilities(ctx context.Context, backendName
 		return
 	}
 
-	// Handle tools (which have a bulk deletion API)
-	if toolNames, exists := removedByType["tool"]; exists && len(toolNames) > 0 {
-		i.server.DeleteTools(toolNames...)
-		i.sendChangeNotification(ctx, "toolsChange")
+	// Remove the items from the server so they no longer point at the client
+	for capType, names := range removedByType {
+		i.deleteItems(capType, names)
 	}
 
-	// Check if prompts were changed
-	if _, promptsChanged := removedByType["prompt"]; promptsChanged {
-		i.sendChangeNotification(ctx, "promptsChange")
-	}
-
-	// Check if resources or templates were changed
-	_, resourcesChanged := removedByType["resource"]
-	_, templatesChanged := removedByType["template"]
-
-	if resourcesChanged || templatesChanged {
-		i.sendChangeNotification(ctx, "resourcesChange")
-	}
+	i.sendNotifications(checkCapabilityChanges(removedByType))
 }
 
-// extractRawCapabilityNames creates a map of capability names without backend prefixes.
-func extractRawCapabilityNames(
-	name string,
+// extractRawCapabilityNames creates a map of the backend's own names of its capabilities.
+func (i *Interposer) extractRawCapabilityNames(
+	cfg config.Server,
 	capsByType map[string][]string,
 	capType string,
+	capability config.CapabilityType,
 ) map[string]bool {
 	result := make(map[string]bool)
 
-	if capNames, exists := capsByType[capType]; exists {
-		for _, capName := range capNames {
-			// Strip backend name prefix for comparison
-			rawName := capName[len(name)+1:] // +1 for the dot
+	for _, capName := range capsByType[capType] {
+		// Undo the suffix, prefix and alias for comparison
+		requested := i.registry.GetRequestedName(capType, capName)
+		if rawName, ok := cfg.OriginalName(capability, requested); ok {
 			result[rawName] = true
 		}
 	}
@@ -216,7 +470,11 @@ func (i *Interposer) UpdateCapabilityConfig(
 	// If the new config disables the entire server, remove it
 	if newConfig.Disabled() {
 		log.Printf("Server %s is now disabled, removing", name)
+
+		capsByType := i.registry.GetCapabilitiesForBackend(name)
+
 		i.removeBackend(ctx, name)
+		i.sendNotifications(checkCapabilityChanges(capsByType))
 
 		return nil
 	}
@@ -225,37 +483,45 @@ func (i *Interposer) UpdateCapabilityConfig(
 	capsByType := i.registry.GetCapabilitiesForBackend(name)
 
 	// Track changes for notifications
-	toolsChanged := i.processDisabledTools(name, capsByType, newConfig)
-	promptsChanged := false
-	resourcesChanged := false
-	templatesChanged := false
+	toolsChanged := i.processDisabledItems(name, capsByType, newConfig, "tool", config.CapabilityTypeTool)
+	promptsChanged := i.processDisabledItems(name, capsByType, newConfig, "prompt", config.CapabilityTypePrompt)
+	resourcesChanged := i.processDisabledItems(name, capsByType, newConfig, "resource", config.CapabilityTypeResource)
+	templatesChanged := i.processDisabledItems(name, capsByType, newConfig, "template", config.CapabilityTypeTemplate)
 
 	// For capabilities that are now enabled but weren't before, we need to
-	// re-initialize the client to get the full list of capabilities
+	// list the backend's items again
 	needsReinit := !config.CompareCapability(oldConfig.Enable, newConfig.Enable) ||
 		!config.CompareCapability(oldConfig.Disable, newConfig.Disable)
 
-	// Re-initialize if needed
+	// Re-list if needed
 	if needsReinit {
 		toolsChanged, promptsChanged, resourcesChanged, templatesChanged = i.handleReinitCapabilities(
 			ctx, name, mcpClient, capsByType, newConfig, toolsChanged, promptsChanged, resourcesChanged, templatesChanged,
 		)
 	}
 
+	// Remember the configuration the capabilities now reflect
+	i.mu.Lock()
+
+	if _, exists := i.clients[name]; exists {
+		i.configs[name] = newConfig
+	}
+	i.mu.Unlock()
+
 	// Send notifications for changes if needed
 	if toolsChanged {
 		log.Printf("Publishing tools change notification for %s", name)
-		i.sendChangeNotification(ctx, "toolsChange")
+		i.sendChangeNotification(mcp.MethodNotificationToolsListChanged)
 	}
 
 	if promptsChanged {
 		log.Printf("Publishing prompts change notification for %s", name)
-		i.sendChangeNotification(ctx, "promptsChange")
+		i.sendChangeNotification(mcp.MethodNotificationPromptsListChanged)
 	}
 
 	if resourcesChanged || templatesChanged {
 		log.Printf("Publishing resources change notification for %s", name)
-		i.sendChangeNotification(ctx, "resourcesChange")
+		i.sendChangeNotification(mcp.MethodNotificationResourcesListChanged)
 	}
 
 	return nil
@@ -289,20 +555,6 @@ func checkCapabilityChanges(capsByType map[string][]string) (bool, bool, bool, b
 	return toolsChanged, promptsChanged, resourcesChanged, templatesChanged
 }
 
-// createDefaultOldConfig creates a default config for comparison purposes.
-func createDefaultOldConfig(name string, newConfig config.Server) config.Server {
-	return config.Server{
-		Name:    name,
-		Type:    newConfig.Type,
-		Command: newConfig.Command,
-		Args:    newConfig.Args,
-		Env:     newConfig.Env,
-		URL:     newConfig.URL,
-		Enable:  &config.Capability{},
-		Disable: &config.Capability{},
-	}
-}
-
 // capabilityChanges tracks changes to different capability types.
 type capabilityChanges struct {
 	toolsChanged     bool
@@ -321,10 +573,20 @@ func (c *capabilityChanges) updateChanges(tc, pc, rc, tec bool) {
 
 // Reconfigure updates the interposer with a new set of server configurations.
 func (i *Interposer) Reconfigure(ctx context.Context, serverConfigs []config.Server) error {
+	i.lifecycle.Lock()
+	defer i.lifecycle.Unlock()
+
+	// Clients are notified once of everything that changed
+	i.notifier.hold()
+	defer i.notifier.release()
+
 	// Collect current backends and prepare new config map
 	currentBackends := i.getCurrentBackends()
 	newConfigMap := prepareNewConfigMap(serverConfigs)
 
+	// Backends being reconnected keep reconnecting with their new config
+	i.processReconnectingBackends(newConfigMap)
+
 	// Track changes for notifications
 	changes := &capabilityChanges{}
 
@@ -334,7 +596,7 @@ func (i *Interposer) Reconfigure(ctx context.Context, serverConfigs []config.Ser
 	i.processNewBackends(ctx, newConfigMap, changes)
 
 	// Send notifications for all changes
-	i.sendNotifications(ctx,
+	i.sendNotifications(
 		changes.toolsChanged,
 		changes.promptsChanged,
 		changes.resourcesChanged,
@@ -345,6 +607,9 @@ func (i *Interposer) Reconfigure(ctx context.Context, serverConfigs []config.Ser
 
 // Close closes all connections.
 func (i *Interposer) Close() error {
+	// Stop reconnecting before closing the clients
+	i.cancel()
+
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
@@ -371,30 +636,55 @@ func (i *Interposer) addClientCapabilities(
 		return
 	}
 
+	// Names of the items offered by type, to validate the configured patterns
+	offered := make(map[config.CapabilityType][]string)
+
 	// Add tools if supported and not disabled
 	if result.Capabilities.Tools != nil {
-		if err := i.addClientTools(ctx, mcpClient, cfg); err != nil {
+		if err := i.addClientTools(ctx, mcpClient, cfg, offered); err != nil {
 			log.Printf("Warning: failed to add tools from %s: %v", cfg.Name, err)
 		}
 	}
 
 	// Add prompts if supported
 	if result.Capabilities.Prompts != nil {
-		if err := i.addClientPrompts(ctx, mcpClient, cfg); err != nil {
+		if err := i.addClientPrompts(ctx, mcpClient, cfg, offered); err != nil {
 			log.Printf("Warning: failed to add prompts from %s: %v", cfg.Name, err)
 		}
 	}
 
 	// Add resources if supported
 	if result.Capabilities.Resources != nil {
-		if err := i.addClientResources(ctx, mcpClient, cfg); err != nil {
+		if err := i.addClientResources(ctx, mcpClient, cfg, offered); err != nil {
 			log.Printf("Warning: failed to add resources from %s: %v", cfg.Name, err)
 		}
 
-		if err := i.addClientResourceTemplates(ctx, mcpClient, cfg); err != nil {
+		if err := i.addClientResourceTemplates(ctx, mcpClient, cfg, offered); err != nil {
 			log.Printf("Warning: failed to add resource templates from %s: %v", cfg.Name, err)
 		}
 	}
+
+	reportUnmatchedPatterns(cfg, offered)
+}
+
+// reportUnmatchedPatterns warns about enable and disable patterns and tool
+// overrides that match none of the items the backend offers, which are likely typos.
+func reportUnmatchedPatterns(cfg config.Server, offered map[config.CapabilityType][]string) {
+	for list, capability := range map[string]*config.Capability{"enable": cfg.Enable, "disable": cfg.Disable} {
+		for capType, patterns := range capability.Unmatched(offered) {
+			for _, pattern := range patterns {
+				log.Printf("Warning: %s %s pattern %q of %s matches nothing", list, capType, pattern, cfg.Name)
+			}
+		}
+	}
+
+	if tools, known := offered[config.CapabilityTypeTool]; known {
+		for name := range cfg.Overrides {
+			if !slices.Contains(tools, name) {
+				log.Printf("Warning: override of %s matches no tool of %s", name, cfg.Name)
+			}
+		}
+	}
 }
 
 // getCurrentBackends returns a map of current backend names.
@@ -410,7 +700,8 @@ func (i *Interposer) getCurrentBackends() map[string]bool {
 	return currentBackends
 }
 
-// handleReinitCapabilities handles the re-initialization of capabilities for a backend.
+// handleReinitCapabilities registers the newly enabled capabilities of a
+// backend, listing those it advertised when it was initialized.
 func (i *Interposer) handleReinitCapabilities(
 	ctx context.Context,
 	name string,
@@ -419,12 +710,16 @@ func (i *Interposer) handleReinitCapabilities(
 	newConfig config.Server,
 	toolsChanged, promptsChanged, resourcesChanged, templatesChanged bool,
 ) (bool, bool, bool, bool) {
-	log.Printf("Capability configuration changed for backend %s, re-initializing", name)
+	log.Printf("Capability configuration changed for backend %s, re-listing", name)
 
-	// Initialize the client to get capability info
-	initResult, err := Initialize(ctx, mcpClient, i.ImplementationInfo(), name)
-	if err != nil {
-		log.Printf("Warning: failed to re-initialize client: %v", err)
+	// Initializing the live connection again would break it, the result
+	// of its initialization tells what it supports
+	i.mu.RLock()
+	initResult, exists := i.results[name]
+	i.mu.RUnlock()
+
+	if !exists {
+		log.Printf("Warning: capabilities of backend %s are unknown", name)
 
 		return toolsChanged, promptsChanged, resourcesChanged, templatesChanged
 	}
@@ -458,7 +753,7 @@ func (i *Interposer) handleToolReinit(
 	newConfig config.Server,
 	toolsChanged bool,
 ) bool {
-	currentTools := extractRawCapabilityNames(name, capsByType, "tool")
+	currentTools := i.extractRawCapabilityNames(newConfig, capsByType, "tool", config.CapabilityTypeTool)
 
 	changed, err := i.processNewTools(ctx, name, mcpClient, currentTools, newConfig)
 	if err != nil {
@@ -583,12 +878,11 @@ func (i *Interposer) processUpdatedBackends(
 	}
 }
 
-// sendChangeNotification sends a capability change notification to clients.
-func (i *Interposer) sendChangeNotification(ctx context.Context, notificationType string) {
-	params := map[string]any{}
-	if err := i.server.SendNotificationToClient(ctx, notificationType, params); err != nil {
-		log.Printf("Warning: failed to send %s notification: %v", notificationType, err)
-	}
+// sendChangeNotification notifies clients that a list of capabilities changed.
+// While notifications are held, for example during a Reconfigure, the
+// notification is sent once they are released.
+func (i *Interposer) sendChangeNotification(method string) {
+	i.notifier.changed(method)
 }
 
 // verifyClientExists checks if a client exists in the clients map.
@@ -602,24 +896,40 @@ func (i *Interposer) verifyClientExists(name string) bool {
 }
 
 // updateExistingBackend updates an existing backend with new configuration.
+// Backends whose configuration did not change are left alone, backends whose
+// naming or enable and disable lists changed are updated in place, and only
+// backends connected differently are restarted.
 func (i *Interposer) updateExistingBackend(
 	ctx context.Context,
 	name string,
 	newConfig config.Server,
 	changes *capabilityChanges,
 ) {
-	// For now, we always do a full restart since we can't easily
-	// compare all the client parameters
-	oldConfig := createDefaultOldConfig(name, newConfig)
-	needsFullRestart := true
+	i.mu.RLock()
+	oldConfig, exists := i.configs[name]
+	i.mu.RUnlock()
 
-	if needsFullRestart {
+	if !exists || !oldConfig.SameConnection(&newConfig) {
 		tc, pc, rc, tec := i.processBackendRestart(ctx, name, newConfig)
 		changes.updateChanges(tc, pc, rc, tec)
-	} else {
-		// Only capability settings have changed
+
+		return
+	}
+
+	// Settings read when used, like the backoff, apply right away
+	i.processSettingsUpdate(name, oldConfig, newConfig)
+
+	switch {
+	case !oldConfig.SameItems(&newConfig):
+		tc, pc, rc, tec := i.processItemsUpdate(ctx, name, newConfig)
+		changes.updateChanges(tc, pc, rc, tec)
+
+	case !oldConfig.SameCapabilities(&newConfig):
 		tc, pc, rc, tec := i.processCapabilityUpdate(ctx, name, oldConfig, newConfig)
 		changes.updateChanges(tc, pc, rc, tec)
+
+	default:
+		log.Printf("Backend %s is unchanged", name)
 	}
 }
 
@@ -635,35 +945,45 @@ func (i *Interposer) processNewBackends(
 	}
 }
 
-// processDisabledTools handles tools that need to be disabled.
-func (i *Interposer) processDisabledTools(
+// processDisabledItems removes the items of a type that are no longer enabled.
+func (i *Interposer) processDisabledItems(
 	name string,
 	capsByType map[string][]string,
 	newConfig config.Server,
+	capType string,
+	capability config.CapabilityType,
 ) bool {
-	toolsChanged := false
-	currentTools := extractRawCapabilityNames(name, capsByType, "tool")
+	var toRemove []string
 
-	var toolsToRemove []string
+	for _, fullName := range capsByType[capType] {
+		// Undo the suffix, prefix and alias to match the configuration
+		rawName, ok := newConfig.OriginalName(capability, i.registry.GetRequestedName(capType, fullName))
+		if !ok {
+			continue
+		}
 
-	for toolName := range currentTools {
-		if !newConfig.Enabled(config.CapabilityTypeTool, toolName) {
-			log.Printf("Tool %s is now disabled", toolName)
-			fullName := fmt.Sprintf("%s.%s", name, toolName)
-			toolsToRemove = append(toolsToRemove, fullName)
-			toolsChanged = true
+		enabled := newConfig.Enabled(capability, rawName)
+
+		// Tools are also filtered on their annotations
+		if item, exists := i.catalog.get(capType, fullName); exists {
+			if tool, ok := item.(server.ServerTool); ok {
+				enabled = i.toolEnabled(newConfig, rawName, toolAnnotations(tool.Tool))
+			}
+		}
+
+		if !enabled {
+			log.Printf("%s %s is now disabled", capability, rawName)
+			toRemove = append(toRemove, fullName)
 
 			// Remove from registry
-			i.registry.RemoveCapability("tool", fullName)
+			i.registry.RemoveCapability(capType, fullName)
 		}
 	}
 
-	// Remove now-disabled tools
-	if len(toolsToRemove) > 0 {
-		i.server.DeleteTools(toolsToRemove...)
-	}
+	// Remove now-disabled items
+	i.deleteItems(capType, toRemove)
 
-	return toolsChanged
+	return len(toRemove) > 0
 }
 
 // processNewTools adds newly enabled tools.
@@ -679,21 +999,38 @@ func (i *Interposer) processNewTools(
 	// Get tools from client
 	toolReq := mcp.ListToolsRequest{}
 
-	result, err := mcpClient.ListTools(ctx, toolReq)
+	listCtx, cancel := withTimeout(ctx, newConfig.Timeouts.List)
+	defer cancel()
+
+	result, err := mcpClient.ListTools(listCtx, toolReq)
 	if err != nil {
 		return false, fmt.Errorf("failed to list tools: %w", err)
 	}
 
 	// Add newly enabled tools
 	for _, tool := range result.Tools {
-		if newConfig.Enabled(config.CapabilityTypeTool, tool.Name) && !currentTools[tool.Name] {
+		if itemEnabled(i, newConfig, config.CapabilityTypeTool, tool) && !currentTools[tool.Name] {
 			// This is a newly enabled tool
 			log.Printf("Adding newly enabled tool: %s", tool.Name)
 
-			toolsChanged = true
-
 			// Register the tool
-			i.RegisterTool(name, transform(name, tool), handleTool(tool, mcpClient))
+			transformedTool, err := transform(newConfig, tool)
+			if err != nil {
+				log.Printf("Skipping tool %s: %v", tool.Name, err)
+
+				continue
+			}
+
+			handler := handleTool(newConfig, tool, mcpClient, i.toolAllowed(name, tool))
+			if err := i.RegisterTool(name, transformedTool, handler); err != nil {
+				log.Printf("Failed to register tool %s: %v", tool.Name, err)
+
+				continue
+			}
+
+			// The collision policy may have skipped it
+			exposed := newConfig.ExposedName(config.CapabilityTypeTool, tool.Name)
+			toolsChanged = toolsChanged || i.registry.Provides(name, "tool", exposed)
 		}
 	}
 
@@ -709,12 +1046,15 @@ func (i *Interposer) processNewPrompts(
 	newConfig config.Server,
 ) (bool, error) {
 	promptsChanged := false
-	currentPrompts := extractRawCapabilityNames(name, capsByType, "prompt")
+	currentPrompts := i.extractRawCapabilityNames(newConfig, capsByType, "prompt", config.CapabilityTypePrompt)
 
 	// Get prompts from client
 	promptReq := mcp.ListPromptsRequest{}
 
-	result, err := mcpClient.ListPrompts(ctx, promptReq)
+	listCtx, cancel := withTimeout(ctx, newConfig.Timeouts.List)
+	defer cancel()
+
+	result, err := mcpClient.ListPrompts(listCtx, promptReq)
 	if err != nil {
 		return false, fmt.Errorf("failed to list prompts: %w", err)
 	}
@@ -725,11 +1065,23 @@ func (i *Interposer) processNewPrompts(
 			// This is a newly enabled prompt
 			log.Printf("Adding newly enabled prompt: %s", prompt.Name)
 
-			promptsChanged = true
-
 			// Register the prompt
-			transformedPrompt := transform(name, prompt)
-			i.RegisterPrompt(name, transformedPrompt, handlePrompt(prompt, mcpClient))
+			transformedPrompt, err := transform(newConfig, prompt)
+			if err != nil {
+				log.Printf("Skipping prompt %s: %v", prompt.Name, err)
+
+				continue
+			}
+
+			if err := i.RegisterPrompt(name, transformedPrompt, handlePrompt(newConfig, prompt, mcpClient)); err != nil {
+				log.Printf("Failed to register prompt %s: %v", prompt.Name, err)
+
+				continue
+			}
+
+			// The collision policy may have skipped it
+			exposed := newConfig.ExposedName(config.CapabilityTypePrompt, prompt.Name)
+			promptsChanged = promptsChanged || i.registry.Provides(name, "prompt", exposed)
 		}
 	}
 
@@ -745,12 +1097,15 @@ func (i *Interposer) processNewResources(
 	newConfig config.Server,
 ) (bool, error) {
 	resourcesChanged := false
-	currentResources := extractRawCapabilityNames(name, capsByType, "resource")
+	currentResources := i.extractRawCapabilityNames(newConfig, capsByType, "resource", config.CapabilityTypeResource)
 
 	// Get resources from client
 	resourceReq := mcp.ListResourcesRequest{}
 
-	result, err := mcpClient.ListResources(ctx, resourceReq)
+	listCtx, cancel := withTimeout(ctx, newConfig.Timeouts.List)
+	defer cancel()
+
+	result, err := mcpClient.ListResources(listCtx, resourceReq)
 	if err != nil {
 		return false, fmt.Errorf("failed to list resources: %w", err)
 	}
@@ -761,14 +1116,27 @@ func (i *Interposer) processNewResources(
 			// This is a newly enabled resource
 			log.Printf("Adding newly enabled resource: %s", resource.Name)
 
-			resourcesChanged = true
-
 			// Register the resource
-			i.RegisterResource(
+			transformedResource, err := transform(newConfig, resource)
+			if err != nil {
+				log.Printf("Skipping resource %s: %v", resource.Name, err)
+
+				continue
+			}
+
+			if err := i.RegisterResource(
 				name,
-				transform(name, resource),
-				handleResource(name, resource, mcpClient),
-			)
+				transformedResource,
+				handleResource(newConfig, resource, mcpClient),
+			); err != nil {
+				log.Printf("Failed to register resource %s: %v", resource.Name, err)
+
+				continue
+			}
+
+			// The collision policy may have skipped it
+			exposed := newConfig.ExposedName(config.CapabilityTypeResource, resource.Name)
+			resourcesChanged = resourcesChanged || i.registry.Provides(name, "resource", exposed)
 		}
 	}
 
@@ -784,12 +1152,15 @@ func (i *Interposer) processNewTemplates(
 	newConfig config.Server,
 ) (bool, error) {
 	templatesChanged := false
-	currentTemplates := extractRawCapabilityNames(name, capsByType, "template")
+	currentTemplates := i.extractRawCapabilityNames(newConfig, capsByType, "template", config.CapabilityTypeTemplate)
 
 	// Get templates from client
 	templateReq := mcp.ListResourceTemplatesRequest{}
 
-	result, err := mcpClient.ListResourceTemplates(ctx, templateReq)
+	listCtx, cancel := withTimeout(ctx, newConfig.Timeouts.List)
+	defer cancel()
+
+	result, err := mcpClient.ListResourceTemplates(listCtx, templateReq)
 	if err != nil {
 		return false, fmt.Errorf("failed to list resource templates: %w", err)
 	}
@@ -800,15 +1171,27 @@ func (i *Interposer) processNewTemplates(
 			// This is a newly enabled template
 			log.Printf("Adding newly enabled resource template: %s", template.Name)
 
-			templatesChanged = true
-
 			// Register the template
-			transformedTemplate := transform(name, template)
-			i.RegisterResourceTemplate(
+			transformedTemplate, err := transform(newConfig, template)
+			if err != nil {
+				log.Printf("Skipping resource template %s: %v", template.Name, err)
+
+				continue
+			}
+
+			if err := i.RegisterResourceTemplate(
 				name,
 				transformedTemplate,
-				handleResource(name, template, mcpClient),
-			)
+				handleResource(newConfig, template, mcpClient),
+			); err != nil {
+				log.Printf("Failed to register resource template %s: %v", template.Name, err)
+
+				continue
+			}
+
+			// The collision policy may have skipped it
+			exposed := newConfig.ExposedName(config.CapabilityTypeTemplate, template.Name)
+			templatesChanged = templatesChanged || i.registry.Provides(name, "template", exposed)
 		}
 	}
 
@@ -817,7 +1200,6 @@ func (i *Interposer) processNewTemplates(
 
 // sendNotifications sends capability change notifications based on what changed.
 func (i *Interposer) sendNotifications(
-	ctx context.Context,
 	toolsChanged,
 	promptsChanged,
 	resourcesChanged,
@@ -825,17 +1207,17 @@ func (i *Interposer) sendNotifications(
 ) {
 	if toolsChanged {
 		log.Printf("Publishing tools change notification")
-		i.sendChangeNotification(ctx, "toolsChange")
+		i.sendChangeNotification(mcp.MethodNotificationToolsListChanged)
 	}
 
 	if promptsChanged {
 		log.Printf("Publishing prompts change notification")
-		i.sendChangeNotification(ctx, "promptsChange")
+		i.sendChangeNotification(mcp.MethodNotificationPromptsListChanged)
 	}
 
 	if resourcesChanged || templatesChanged {
 		log.Printf("Publishing resources change notification")
-		i.sendChangeNotification(ctx, "resourcesChange")
+		i.sendChangeNotification(mcp.MethodNotificationResourcesListChanged)
 	}
 }
 
@@ -848,6 +1230,7 @@ func (i *Interposer) processRemovedBackend(ctx context.Context, name string) (bo
 	toolsChanged, promptsChanged, resourcesChanged, templatesChanged := checkCapabilityChanges(capsByType)
 
 	i.removeBackend(ctx, name)
+	i.subscriptions.forget(name)
 
 	return toolsChanged, promptsChanged, resourcesChanged, templatesChanged
 }
@@ -901,10 +1284,73 @@ func (i *Interposer) processCapabilityUpdate(
 		return i.processBackendRestart(ctx, name, newConfig)
 	}
 
-	// Check what got updated in the registry
+	// UpdateCapabilityConfig already notified clients of what it changed
+	return false, false, false, false
+}
+
+// processSettingsUpdate stores the new configuration of a backend that keeps
+// its connection, restarting its health checks if they changed.
+func (i *Interposer) processSettingsUpdate(name string, oldConfig, newConfig config.Server) {
+	i.mu.Lock()
+
+	mcpClient, exists := i.clients[name]
+	if exists {
+		i.configs[name] = newConfig
+	}
+
+	i.mu.Unlock()
+
+	if !exists || sameHealthCheck(oldConfig.HealthCheck, newConfig.HealthCheck) {
+		return
+	}
+
+	log.Printf("Health checks of backend %s changed", name)
+
+	// The previous checks stop once they see the new configuration
+	i.registry.SetBackendHealth(name, HealthHealthy)
+
+	if newConfig.HealthCheck.IsEnabled() {
+		go i.monitorHealth(name, mcpClient, newConfig.HealthCheck.WithDefaults())
+	}
+}
+
+// processItemsUpdate registers the items of a backend again, as the names
+// they are exposed under or their handlers changed. The backend's connection
+// is kept, its items are listed again.
+func (i *Interposer) processItemsUpdate(
+	ctx context.Context,
+	name string,
+	newConfig config.Server,
+) (bool, bool, bool, bool) {
+	log.Printf("Updating items of backend: %s", name)
+
+	i.mu.RLock()
+	mcpClient, exists := i.clients[name]
+	result := i.results[name]
+	i.mu.RUnlock()
+
+	if !exists || result == nil {
+		return i.processBackendRestart(ctx, name, newConfig)
+	}
+
+	// Check which capability types this backend had
 	capsByType := i.registry.GetCapabilitiesForBackend(name)
+	toolsChanged, promptsChanged, resourcesChanged, templatesChanged := checkCapabilityChanges(capsByType)
 
-	return checkCapabilityChanges(capsByType)
+	for capType, names := range i.registry.RemoveBackendCapabilities(name) {
+		i.deleteItems(capType, names)
+	}
+
+	i.addClientCapabilities(ctx, mcpClient, result, newConfig)
+
+	// Check which capability types were added
+	capsByType = i.registry.GetCapabilitiesForBackend(name)
+	newToolsChanged, newPromptsChanged, newResourcesChanged, newTemplatesChanged := checkCapabilityChanges(capsByType)
+
+	return toolsChanged || newToolsChanged,
+		promptsChanged || newPromptsChanged,
+		resourcesChanged || newResourcesChanged,
+		templatesChanged || newTemplatesChanged
 }
 
 // processNewBackend adds a new backend that wasn't previously configured.
@@ -929,11 +1375,17 @@ func (i *Interposer) processNewBackend(
 
 // removeBackend removes a backend client from the interposer.
 func (i *Interposer) removeBackend(ctx context.Context, name string) {
+	// A backend being removed must not be reconnected
+	i.cancelReconnect(name)
+
 	// Get and close the client under lock
 	var clientExists bool
 
 	i.mu.Lock()
 
+	delete(i.configs, name)
+	delete(i.results, name)
+
 	client, clientExists := i.clients[name]
 	if clientExists {
 		// Close the client
@@ -951,6 +1403,8 @@ func (i *Interposer) removeBackend(ctx context.Context, name string) {
 		return
 	}
 
+	i.registry.RemoveBackendHealth(name)
+
 	// Remove capabilities outside the lock
 	i.RemoveTrackedCapabilities(ctx, name)
 }
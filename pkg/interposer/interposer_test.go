@@ -3,7 +3,11 @@ package interposer
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -15,7 +19,7 @@ import (
 
 // MockMCPClient is a mock implementation of the MCPClient interface for testing.
 type MockMCPClient struct {
-	initializeCalled            bool
+	initializeCalls             atomic.Int32
 	listToolsCalled             bool
 	listPromptsCalled           bool
 	listResourcesCalled         bool
@@ -33,7 +37,7 @@ func (m *MockMCPClient) Initialize(
 	_ context.Context,
 	_ mcp.InitializeRequest,
 ) (*mcp.InitializeResult, error) {
-	m.initializeCalled = true
+	m.initializeCalls.Add(1)
 
 	return &mcp.InitializeResult{
 		ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
@@ -586,3 +590,400 @@ func createMockClient() *MockMCPClient {
 		},
 	}
 }
+
+// fakeSession is a frontend client session recording its notifications.
+type fakeSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func newFakeSession(id string) *fakeSession {
+	return &fakeSession{
+		id:            id,
+		notifications: make(chan mcp.JSONRPCNotification, 100),
+	}
+}
+
+// Initialize implements the server.ClientSession interface.
+func (s *fakeSession) Initialize() {}
+
+// Initialized implements the server.ClientSession interface.
+func (s *fakeSession) Initialized() bool {
+	return true
+}
+
+// NotificationChannel implements the server.ClientSession interface.
+func (s *fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+// SessionID implements the server.ClientSession interface.
+func (s *fakeSession) SessionID() string {
+	return s.id
+}
+
+// methods drains the notifications received so far, returning their methods.
+func (s *fakeSession) methods() []string {
+	var methods []string
+
+	for {
+		select {
+		case notification := <-s.notifications:
+			methods = append(methods, notification.Method)
+		default:
+			return methods
+		}
+	}
+}
+
+func TestNotificationMethods(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(mockClientFactory()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	session := newFakeSession("session")
+	require.NoError(t, interposerInstance.Server().RegisterSession(context.Background(), session))
+
+	listChanged := []string{
+		"notifications/tools/list_changed",
+		"notifications/prompts/list_changed",
+		"notifications/resources/list_changed",
+	}
+
+	// Adding two backends notifies once per type
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{
+		{Name: "first", Type: config.ServerTypeStdio},
+		{Name: "second", Type: config.ServerTypeStdio},
+	}))
+
+	assert.Equal(t, listChanged, session.methods())
+
+	// Removing them does as well
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), nil))
+
+	assert.Equal(t, listChanged, session.methods())
+
+	// Nothing changing notifies nothing
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), nil))
+
+	assert.Empty(t, session.methods())
+}
+
+func TestInitializeAdvertisesListChanged(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer("TestInterposer", "1.0.0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	message := interposerInstance.Server().HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "initialize",
+		"params": {
+			"protocolVersion": "`+mcp.LATEST_PROTOCOL_VERSION+`",
+			"clientInfo": {"name": "test", "version": "1.0.0"}
+		}
+	}`))
+
+	response, ok := message.(mcp.JSONRPCResponse)
+	require.True(t, ok, "initialize should succeed, got %T", message)
+
+	result, ok := response.Result.(mcp.InitializeResult)
+	require.True(t, ok, "result should be an InitializeResult, got %T", response.Result)
+
+	require.NotNil(t, result.Capabilities.Tools)
+	assert.True(t, result.Capabilities.Tools.ListChanged)
+	require.NotNil(t, result.Capabilities.Prompts)
+	assert.True(t, result.Capabilities.Prompts.ListChanged)
+	require.NotNil(t, result.Capabilities.Resources)
+	assert.True(t, result.Capabilities.Resources.ListChanged)
+	assert.True(t, result.Capabilities.Resources.Subscribe)
+}
+
+func TestReconfigureDiff(t *testing.T) {
+	t.Parallel()
+
+	var created atomic.Int32
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(countingClientFactory(&created)),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	serverConfig := config.Server{
+		Name:    "test-server",
+		Type:    config.ServerTypeStdio,
+		Command: "test-server",
+	}
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+	assert.Equal(t, int32(1), created.Load())
+
+	// An unchanged backend is left alone
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+	assert.Equal(t, int32(1), created.Load())
+	assert.NotNil(t, interposerInstance.Server().GetTool("test-server-test-tool"))
+
+	// Changing what is disabled updates the backend in place
+	serverConfig.Disable = &config.Capability{
+		Capabilities: map[config.CapabilityType][]string{
+			config.CapabilityTypeTool: {"test-tool"},
+		},
+	}
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+	assert.Equal(t, int32(1), created.Load())
+	assert.Nil(t, interposerInstance.Server().GetTool("test-server-test-tool"))
+
+	serverConfig.Disable = nil
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+	assert.Equal(t, int32(1), created.Load())
+	assert.NotNil(t, interposerInstance.Server().GetTool("test-server-test-tool"))
+
+	// Changing how it is launched restarts it
+	serverConfig.Args = []string{"--verbose"}
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+	assert.Equal(t, int32(2), created.Load())
+	assert.NotNil(t, interposerInstance.Server().GetTool("test-server-test-tool"))
+}
+
+func TestReconfigureKeepsConnection(t *testing.T) {
+	t.Parallel()
+
+	mockClient := createMockClient()
+
+	var created atomic.Int32
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(func(_ config.Server) (client.MCPClient, error) {
+			created.Add(1)
+
+			return mockClient, nil
+		}),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	serverConfig := config.Server{
+		Name:    "test-server",
+		Type:    config.ServerTypeStdio,
+		Command: "test-server",
+	}
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+
+	// Enabling and disabling items lists them again without initializing
+	// the backend a second time
+	serverConfig.Disable = &config.Capability{
+		Capabilities: map[config.CapabilityType][]string{
+			config.CapabilityTypeTool: {"test-tool"},
+		},
+	}
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+	assert.Nil(t, interposerInstance.Server().GetTool("test-server-test-tool"))
+
+	serverConfig.Disable = nil
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+	assert.NotNil(t, interposerInstance.Server().GetTool("test-server-test-tool"))
+	assert.Equal(t, int32(1), mockClient.initializeCalls.Load())
+
+	// Renamed items are registered again under their new names
+	prefix := "renamed"
+	serverConfig.Prefix = &prefix
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+	assert.Nil(t, interposerInstance.Server().GetTool("test-server-test-tool"))
+	assert.NotNil(t, interposerInstance.Server().GetTool("renamed-test-tool"))
+
+	// Timeouts and health checks are stored with the config
+	serverConfig.Timeouts.Call = config.Duration(time.Minute)
+	serverConfig.HealthCheck = &config.HealthCheck{Interval: config.Duration(time.Hour)}
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+
+	interposerInstance.mu.RLock()
+	stored := interposerInstance.configs["test-server"]
+	interposerInstance.mu.RUnlock()
+
+	assert.Equal(t, serverConfig.Timeouts, stored.Timeouts)
+	assert.True(t, stored.HealthCheck.IsEnabled())
+
+	assert.Equal(t, int32(1), created.Load())
+	assert.Equal(t, int32(1), mockClient.initializeCalls.Load())
+}
+
+// listResult sends a list request to the interposer's server, returning its result.
+func listResult(t *testing.T, interposerInstance *Interposer, method string) any {
+	t.Helper()
+
+	message := interposerInstance.Server().HandleMessage(
+		context.Background(),
+		[]byte(`{"jsonrpc": "2.0", "id": 1, "method": "`+method+`"}`),
+	)
+
+	response, ok := message.(mcp.JSONRPCResponse)
+	require.True(t, ok, "%s should succeed, got %T", method, message)
+
+	return response.Result
+}
+
+// listedNames returns the names of the prompts, resources and templates listed by the server.
+func listedNames(t *testing.T, interposerInstance *Interposer) []string {
+	t.Helper()
+
+	var names []string
+
+	prompts, ok := listResult(t, interposerInstance, "prompts/list").(mcp.ListPromptsResult)
+	require.True(t, ok)
+
+	for _, prompt := range prompts.Prompts {
+		names = append(names, prompt.Name)
+	}
+
+	resources, ok := listResult(t, interposerInstance, "resources/list").(mcp.ListResourcesResult)
+	require.True(t, ok)
+
+	for _, resource := range resources.Resources {
+		names = append(names, resource.Name)
+	}
+
+	templates, ok := listResult(t, interposerInstance, "resources/templates/list").(mcp.ListResourceTemplatesResult)
+	require.True(t, ok)
+
+	for _, template := range templates.ResourceTemplates {
+		names = append(names, template.Name)
+	}
+
+	return names
+}
+
+func TestRemovedBackendItemsAreUnlisted(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(mockClientFactory()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{
+		{Name: "first", Type: config.ServerTypeStdio},
+		{Name: "second", Type: config.ServerTypeStdio},
+	}))
+
+	assert.ElementsMatch(t, []string{
+		"first.test-prompt", "first-Test Resource", "first-Test Template",
+		"second.test-prompt", "second-Test Resource", "second-Test Template",
+	}, listedNames(t, interposerInstance))
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{
+		{Name: "second", Type: config.ServerTypeStdio},
+	}))
+
+	assert.ElementsMatch(t, []string{
+		"second.test-prompt", "second-Test Resource", "second-Test Template",
+	}, listedNames(t, interposerInstance))
+}
+
+func TestRemovedTemplatesKeepConcurrentOnes(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer("TestInterposer", "1.0.0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	const templates = 50
+
+	var wait sync.WaitGroup
+
+	wait.Add(2)
+
+	// Templates are registered while another backend's are removed, which
+	// replaces the templates of the server as a whole
+	go func() {
+		defer wait.Done()
+
+		for index := range templates {
+			template := mcp.NewResourceTemplate(fmt.Sprintf("kept://%d/{id}", index), fmt.Sprintf("kept-%d", index))
+			assert.NoError(t, interposerInstance.RegisterResourceTemplate("kept", template, nil))
+		}
+	}()
+
+	go func() {
+		defer wait.Done()
+
+		for index := range templates {
+			template := mcp.NewResourceTemplate(fmt.Sprintf("gone://%d/{id}", index), fmt.Sprintf("gone-%d", index))
+			assert.NoError(t, interposerInstance.RegisterResourceTemplate("gone", template, nil))
+			interposerInstance.RemoveTrackedCapabilities(context.Background(), "gone")
+		}
+	}()
+
+	wait.Wait()
+
+	listed, ok := listResult(t, interposerInstance, "resources/templates/list").(mcp.ListResourceTemplatesResult)
+	require.True(t, ok)
+	assert.Len(t, listed.ResourceTemplates, templates)
+}
+
+func TestDisabledItemsAreUnlisted(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(mockClientFactory()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	serverConfig := config.Server{Name: "test-server", Type: config.ServerTypeStdio}
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+
+	serverConfig.Disable = &config.Capability{
+		Capabilities: map[config.CapabilityType][]string{
+			config.CapabilityTypePrompt:   {"test-prompt"},
+			config.CapabilityTypeResource: {"Test Resource"},
+			config.CapabilityTypeTemplate: {"Test Template"},
+		},
+	}
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+
+	assert.Empty(t, listedNames(t, interposerInstance))
+	assert.NotNil(t, interposerInstance.Server().GetTool("test-server-test-tool"))
+
+	serverConfig.Disable = nil
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+
+	assert.ElementsMatch(t, []string{
+		"test-server.test-prompt", "test-server-Test Resource", "test-server-Test Template",
+	}, listedNames(t, interposerInstance))
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strings"
 
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -67,39 +66,37 @@ func register[Item Items, Handler Handlers](
 			return fmt.Errorf("%w: tool %s: have %T", ErrInvalidHandler, name, handler)
 		}
 
-		interposer.RegisterTool(name, value, handlerFunc)
+		return interposer.RegisterTool(name, value, handlerFunc)
 	case mcp.Prompt:
 		handlerFunc, ok := any(handler).(server.PromptHandlerFunc)
 		if !ok {
 			return fmt.Errorf("%w: prompt %s: have %T", ErrInvalidHandler, name, handler)
 		}
 
-		interposer.RegisterPrompt(name, value, handlerFunc)
+		return interposer.RegisterPrompt(name, value, handlerFunc)
 	case mcp.Resource:
 		handlerFunc, ok := any(handler).(server.ResourceHandlerFunc)
 		if !ok {
 			return fmt.Errorf("%w: resource %s: have %T", ErrInvalidHandler, name, handler)
 		}
 
-		interposer.RegisterResource(name, value, handlerFunc)
+		return interposer.RegisterResource(name, value, handlerFunc)
 	case mcp.ResourceTemplate:
 		handlerFunc, ok := any(handler).(server.ResourceTemplateHandlerFunc)
 		if !ok {
 			return fmt.Errorf("%w: resource template %s: have %T", ErrInvalidHandler, name, handler)
 		}
 
-		interposer.RegisterResourceTemplate(name, value, handlerFunc)
+		return interposer.RegisterResourceTemplate(name, value, handlerFunc)
 	default:
 		return fmt.Errorf("%w: %s: have %T", ErrInvalidHandler, name, handler)
 	}
-
-	return nil
 }
 
 func addItems[Item Items, Handler Handlers](
 	ctx context.Context,
 	interposer *Interposer,
-	name string,
+	cfg config.Server,
 	list func(ctx context.Context, cursor string) ([]Item, string, error),
 	handler func(item Item) Handler,
 ) error {
@@ -112,8 +109,14 @@ func addItems[Item Items, Handler Handlers](
 		}
 
 		for _, item := range items {
-			transformed := transform(name, item)
-			if err := register(interposer, name, transformed, handler(item)); err != nil {
+			transformed, err := transform(cfg, item)
+			if err != nil {
+				log.Printf("Skipping item %s: %v", itemName(item), err)
+
+				continue
+			}
+
+			if err := register(interposer, cfg.Name, transformed, handler(item)); err != nil {
 				log.Printf("Failed to register item %s: %v", itemName(item), err)
 
 				continue
@@ -130,46 +133,51 @@ func addItems[Item Items, Handler Handlers](
 	return nil
 }
 
-func transform[Item Items](name string, item Item) Item {
+// transform returns the item as clients see it, with the server's naming
+// applied. It returns an error if the exposed URI template is malformed.
+func transform[Item Items](cfg config.Server, item Item) (Item, error) {
 	switch value := any(item).(type) {
 	case mcp.Tool:
-		value.Name = fmt.Sprintf("%s-%s", name, value.Name)
+		value = overrideTool(cfg, value)
+		value.Name = cfg.ExposedName(config.CapabilityTypeTool, value.Name)
 
 		if i, ok := any(value).(Item); ok {
-			return i
+			return i, nil
 		}
 
 		log.Printf("Failed to cast tool to Item: %T", value)
 
 	case mcp.Prompt:
-		value.Name = fmt.Sprintf("%s.%s", name, value.Name)
+		value.Name = cfg.ExposedName(config.CapabilityTypePrompt, value.Name)
 
 		if i, ok := any(value).(Item); ok {
-			return i
+			return i, nil
 		}
 
 		log.Printf("Failed to cast prompt to Item: %T", value)
 
 	case mcp.Resource:
-		value.Name = fmt.Sprintf("%s-%s", name, value.Name)
-		value.URI = fmt.Sprintf("%s+%s", name, value.URI)
+		value.Name = cfg.ExposedName(config.CapabilityTypeResource, value.Name)
+		value.URI = cfg.ExposedURI(value.URI)
 
 		if i, ok := any(value).(Item); ok {
-			return i
+			return i, nil
 		}
 
 		log.Printf("Failed to cast resource to Item: %T", value)
 
 	case mcp.ResourceTemplate:
-		value.Name = fmt.Sprintf("%s-%s", name, value.Name)
+		value.Name = cfg.ExposedName(config.CapabilityTypeTemplate, value.Name)
 
-		template := fmt.Sprintf("%s+%s", name, value.URITemplate.Raw())
-		value.URITemplate = &mcp.URITemplate{
-			Template: uritemplate.MustNew(template),
+		template, err := uritemplate.New(cfg.ExposedURI(value.URITemplate.Raw()))
+		if err != nil {
+			return item, fmt.Errorf("invalid URI template of %s: %w", value.Name, err)
 		}
 
+		value.URITemplate = &mcp.URITemplate{Template: template}
+
 		if i, ok := any(value).(Item); ok {
-			return i
+			return i, nil
 		}
 
 		log.Printf("Failed to cast resource template to Item: %T", value)
@@ -177,22 +185,59 @@ func transform[Item Items](name string, item Item) Item {
 	default:
 		log.Printf("Unknown item type: %T", value)
 
-		return item
+		return item, nil
 	}
 
-	return item
+	return item, nil
 }
 
-// addClientItems is a helper function to add the client's items of any type to our server.
-func addClientItems[Item Items, Handler Handlers](
-	ctx context.Context,
+// itemEnabled returns true if the item is enabled by the configuration.
+// Tools are also filtered on their annotations and on safe mode.
+func itemEnabled[Item Items](
+	interposer *Interposer,
+	cfg config.Server,
+	capability config.CapabilityType,
+	item Item,
+) bool {
+	if tool, ok := any(item).(mcp.Tool); ok {
+		// Overridden annotations are filtered on as well
+		return interposer.toolEnabled(cfg, tool.Name, toolAnnotations(overrideTool(cfg, tool)))
+	}
+
+	return cfg.Enabled(capability, itemName(item))
+}
+
+// toolAnnotations returns the effective value of each of the tool's
+// annotation hints, using the defaults of the specification for unset hints.
+func toolAnnotations(tool mcp.Tool) map[string]bool {
+	hint := func(value *bool, fallback bool) bool {
+		if value == nil {
+			return fallback
+		}
+
+		return *value
+	}
+
+	readOnly := hint(tool.Annotations.ReadOnlyHint, false)
+
+	return map[string]bool{
+		config.AnnotationReadOnly: readOnly,
+		// Destructive and idempotent are only meaningful for tools that are not read-only
+		config.AnnotationDestructive: !readOnly && hint(tool.Annotations.DestructiveHint, true),
+		config.AnnotationIdempotent:  readOnly || hint(tool.Annotations.IdempotentHint, false),
+		config.AnnotationOpenWorld:   hint(tool.Annotations.OpenWorldHint, true),
+	}
+}
+
+// enabledItems wraps a request listing the client's items so that it only
+// returns the items enabled by the configuration.
+func enabledItems[Item Items](
 	interposer *Interposer,
 	cfg config.Server,
 	capability config.CapabilityType,
 	request func(ctx context.Context, cursor string) ([]Item, string, error),
-	create func(Item) Handler,
-) error {
-	list := func(ctx context.Context, cursor string) ([]Item, string, error) {
+) func(ctx context.Context, cursor string) ([]Item, string, error) {
+	return func(ctx context.Context, cursor string) ([]Item, string, error) {
 		items, next, err := request(ctx, cursor)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to list %s: %w", capability, err)
@@ -202,7 +247,7 @@ func addClientItems[Item Items, Handler Handlers](
 		var filtered []Item
 
 		for _, item := range items {
-			if cfg.Enabled(capability, itemName(item)) {
+			if itemEnabled(interposer, cfg, capability, item) {
 				filtered = append(filtered, item)
 			} else {
 				log.Printf(
@@ -216,23 +261,82 @@ func addClientItems[Item Items, Handler Handlers](
 
 		return filtered, next, nil
 	}
+}
 
-	// Create the handler function
-	return addItems(ctx, interposer, cfg.Name, list, create)
+// listAllItems collects the items of every page.
+func listAllItems[Item Items](
+	ctx context.Context,
+	list func(ctx context.Context, cursor string) ([]Item, string, error),
+) ([]Item, error) {
+	var (
+		all    []Item
+		cursor string
+	)
+
+	for {
+		items, next, err := list(ctx, cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, items...)
+
+		if next == "" {
+			return all, nil
+		}
+
+		cursor = next
+	}
 }
 
-// addClientTools adds the client's tools to our server.
-func (i *Interposer) addClientTools(
+// addClientItems is a helper function to add the client's items of any type to our server.
+func addClientItems[Item Items, Handler Handlers](
 	ctx context.Context,
-	mcpClient client.MCPClient,
+	interposer *Interposer,
 	cfg config.Server,
+	capability config.CapabilityType,
+	request func(ctx context.Context, cursor string) ([]Item, string, error),
+	create func(Item) Handler,
+	offered map[config.CapabilityType][]string,
 ) error {
-	request := func(ctx context.Context, cursor string) ([]mcp.Tool, string, error) {
+	var names []string
+
+	// Record every item offered, enabled or not, to validate the patterns
+	record := func(ctx context.Context, cursor string) ([]Item, string, error) {
+		items, next, err := request(ctx, cursor)
+		for _, item := range items {
+			names = append(names, itemName(item))
+		}
+
+		return items, next, err
+	}
+
+	list := enabledItems(interposer, cfg, capability, record)
+
+	// Create the handler function
+	if err := addItems(ctx, interposer, cfg, list, create); err != nil {
+		return err
+	}
+
+	offered[capability] = names
+
+	return nil
+}
+
+// listTools returns a request listing the client's tools, each page within timeout.
+func listTools(
+	mcpClient client.MCPClient,
+	timeout config.Duration,
+) func(ctx context.Context, cursor string) ([]mcp.Tool, string, error) {
+	return func(ctx context.Context, cursor string) ([]mcp.Tool, string, error) {
 		req := mcp.ListToolsRequest{}
 		if cursor != "" {
 			req.Params.Cursor = mcp.Cursor(cursor)
 		}
 
+		ctx, cancel := withTimeout(ctx, timeout)
+		defer cancel()
+
 		result, err := mcpClient.ListTools(ctx, req)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to list tools: %w", err)
@@ -249,9 +353,89 @@ func (i *Interposer) addClientTools(
 
 		return result.Tools, string(result.NextCursor), nil
 	}
+}
+
+// listPrompts returns a request listing the client's prompts, each page within timeout.
+func listPrompts(
+	mcpClient client.MCPClient,
+	timeout config.Duration,
+) func(ctx context.Context, cursor string) ([]mcp.Prompt, string, error) {
+	return func(ctx context.Context, cursor string) ([]mcp.Prompt, string, error) {
+		req := mcp.ListPromptsRequest{}
+		if cursor != "" {
+			req.Params.Cursor = mcp.Cursor(cursor)
+		}
+
+		ctx, cancel := withTimeout(ctx, timeout)
+		defer cancel()
+
+		result, err := mcpClient.ListPrompts(ctx, req)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list prompts: %w", err)
+		}
+
+		return result.Prompts, string(result.NextCursor), nil
+	}
+}
+
+// listResources returns a request listing the client's resources, each page within timeout.
+func listResources(
+	mcpClient client.MCPClient,
+	timeout config.Duration,
+) func(ctx context.Context, cursor string) ([]mcp.Resource, string, error) {
+	return func(ctx context.Context, cursor string) ([]mcp.Resource, string, error) {
+		req := mcp.ListResourcesRequest{}
+		if cursor != "" {
+			req.Params.Cursor = mcp.Cursor(cursor)
+		}
+
+		ctx, cancel := withTimeout(ctx, timeout)
+		defer cancel()
+
+		result, err := mcpClient.ListResources(ctx, req)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list resources: %w", err)
+		}
+
+		return result.Resources, string(result.NextCursor), nil
+	}
+}
+
+// listResourceTemplates returns a request listing the client's resource
+// templates, each page within timeout.
+func listResourceTemplates(
+	mcpClient client.MCPClient,
+	timeout config.Duration,
+) func(ctx context.Context, cursor string) ([]mcp.ResourceTemplate, string, error) {
+	return func(ctx context.Context, cursor string) ([]mcp.ResourceTemplate, string, error) {
+		req := mcp.ListResourceTemplatesRequest{}
+		if cursor != "" {
+			req.Params.Cursor = mcp.Cursor(cursor)
+		}
+
+		ctx, cancel := withTimeout(ctx, timeout)
+		defer cancel()
+
+		result, err := mcpClient.ListResourceTemplates(ctx, req)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list resource templates: %w", err)
+		}
+
+		return result.ResourceTemplates, string(result.NextCursor), nil
+	}
+}
+
+// addClientTools adds the client's tools to our server.
+func (i *Interposer) addClientTools(
+	ctx context.Context,
+	mcpClient client.MCPClient,
+	cfg config.Server,
+	offered map[config.CapabilityType][]string,
+) error {
+	request := listTools(mcpClient, cfg.Timeouts.List)
 
 	create := func(tool mcp.Tool) server.ToolHandlerFunc {
-		return handleTool(tool, mcpClient)
+		return handleTool(cfg, tool, mcpClient, i.toolAllowed(cfg.Name, tool))
 	}
 
 	return addClientItems(
@@ -261,6 +445,7 @@ func (i *Interposer) addClientTools(
 		config.CapabilityTypeTool,
 		request,
 		create,
+		offered,
 	)
 }
 
@@ -269,23 +454,12 @@ func (i *Interposer) addClientPrompts(
 	ctx context.Context,
 	mcpClient client.MCPClient,
 	cfg config.Server,
+	offered map[config.CapabilityType][]string,
 ) error {
-	request := func(ctx context.Context, cursor string) ([]mcp.Prompt, string, error) {
-		req := mcp.ListPromptsRequest{}
-		if cursor != "" {
-			req.Params.Cursor = mcp.Cursor(cursor)
-		}
-
-		result, err := mcpClient.ListPrompts(ctx, req)
-		if err != nil {
-			return nil, "", fmt.Errorf("failed to list prompts: %w", err)
-		}
-
-		return result.Prompts, string(result.NextCursor), nil
-	}
+	request := listPrompts(mcpClient, cfg.Timeouts.List)
 
 	create := func(prompt mcp.Prompt) server.PromptHandlerFunc {
-		return handlePrompt(prompt, mcpClient)
+		return handlePrompt(cfg, prompt, mcpClient)
 	}
 
 	return addClientItems(
@@ -295,6 +469,7 @@ func (i *Interposer) addClientPrompts(
 		config.CapabilityTypePrompt,
 		request,
 		create,
+		offered,
 	)
 }
 
@@ -303,23 +478,12 @@ func (i *Interposer) addClientResources(
 	ctx context.Context,
 	mcpClient client.MCPClient,
 	cfg config.Server,
+	offered map[config.CapabilityType][]string,
 ) error {
-	request := func(ctx context.Context, cursor string) ([]mcp.Resource, string, error) {
-		req := mcp.ListResourcesRequest{}
-		if cursor != "" {
-			req.Params.Cursor = mcp.Cursor(cursor)
-		}
-
-		result, err := mcpClient.ListResources(ctx, req)
-		if err != nil {
-			return nil, "", fmt.Errorf("failed to list resources: %w", err)
-		}
-
-		return result.Resources, string(result.NextCursor), nil
-	}
+	request := listResources(mcpClient, cfg.Timeouts.List)
 
 	create := func(resource mcp.Resource) server.ResourceHandlerFunc {
-		return handleResource(cfg.Name, resource, mcpClient)
+		return handleResource(cfg, resource, mcpClient)
 	}
 
 	return addClientItems(
@@ -329,6 +493,7 @@ func (i *Interposer) addClientResources(
 		config.CapabilityTypeResource,
 		request,
 		create,
+		offered,
 	)
 }
 
@@ -337,23 +502,12 @@ func (i *Interposer) addClientResourceTemplates(
 	ctx context.Context,
 	mcpClient client.MCPClient,
 	cfg config.Server,
+	offered map[config.CapabilityType][]string,
 ) error {
-	request := func(ctx context.Context, cursor string) ([]mcp.ResourceTemplate, string, error) {
-		req := mcp.ListResourceTemplatesRequest{}
-		if cursor != "" {
-			req.Params.Cursor = mcp.Cursor(cursor)
-		}
-
-		result, err := mcpClient.ListResourceTemplates(ctx, req)
-		if err != nil {
-			return nil, "", fmt.Errorf("failed to list resource templates: %w", err)
-		}
-
-		return result.ResourceTemplates, string(result.NextCursor), nil
-	}
+	request := listResourceTemplates(mcpClient, cfg.Timeouts.List)
 
 	create := func(template mcp.ResourceTemplate) server.ResourceTemplateHandlerFunc {
-		return handleResource(cfg.Name, template, mcpClient)
+		return handleResource(cfg, template, mcpClient)
 	}
 
 	return addClientItems(
@@ -363,19 +517,37 @@ func (i *Interposer) addClientResourceTemplates(
 		config.CapabilityTypeTemplate,
 		request,
 		create,
+		offered,
 	)
 }
 
 func handleTool(
+	cfg config.Server,
 	tool mcp.Tool,
 	mcpClient client.MCPClient,
+	allowed func() bool,
 ) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	override, _ := cfg.ToolOverride(tool.Name)
+
 	return func(
 		ctx context.Context,
 		request mcp.CallToolRequest,
 	) (*mcp.CallToolResult, error) {
+		// The tool may have been listed before safe mode was enabled
+		if !allowed() {
+			return safeModeError(request.Params.Name), nil
+		}
+
 		request.Params.Name = tool.Name
 
+		// Parameters may have been hidden or renamed by an override
+		if len(override.Parameters) > 0 {
+			request.Params.Arguments = restoreArguments(override, request.GetArguments())
+		}
+
+		ctx, cancel := withTimeout(ctx, cfg.Timeouts.Call)
+		defer cancel()
+
 		result, err := mcpClient.CallTool(ctx, request)
 		if err != nil {
 			return nil, fmt.Errorf("failed to call tool: %w", err)
@@ -386,6 +558,7 @@ func handleTool(
 }
 
 func handlePrompt(
+	cfg config.Server,
 	prompt mcp.Prompt,
 	mcpClient client.MCPClient,
 ) func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
@@ -395,6 +568,9 @@ func handlePrompt(
 	) (*mcp.GetPromptResult, error) {
 		request.Params.Name = prompt.Name
 
+		ctx, cancel := withTimeout(ctx, cfg.Timeouts.Call)
+		defer cancel()
+
 		result, err := mcpClient.GetPrompt(ctx, request)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read prompt: %w", err)
@@ -405,7 +581,7 @@ func handlePrompt(
 }
 
 func handleResource[Item Resource](
-	name string,
+	cfg config.Server,
 	resource Item,
 	mcpClient client.MCPClient,
 ) func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
@@ -417,9 +593,12 @@ func handleResource[Item Resource](
 		case mcp.Resource:
 			request.Params.URI = value.URI
 		case mcp.ResourceTemplate:
-			request.Params.URI = strings.TrimPrefix(request.Params.URI, name+"+")
+			request.Params.URI = cfg.OriginalURI(request.Params.URI)
 		}
 
+		ctx, cancel := withTimeout(ctx, cfg.Timeouts.Read)
+		defer cancel()
+
 		result, err := mcpClient.ReadResource(ctx, request)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read resource: %w", err)
@@ -428,3 +607,12 @@ func handleResource[Item Resource](
 		return result.Contents, nil
 	}
 }
+
+// withTimeout returns a context bounded by the timeout, if it is set.
+func withTimeout(ctx context.Context, timeout config.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout.Duration())
+}
@@ -0,0 +1,577 @@
+package interposer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jkoelker/posuer/pkg/config"
+	"github.com/jkoelker/posuer/pkg/manifest"
+)
+
+// ErrBackendClosed is returned for requests to a backend closed while starting.
+var ErrBackendClosed = errors.New("backend closed while starting")
+
+// lazyClient starts its backend on the first request that needs it, and
+// answers listings from the manifest of the backend while it is not running.
+// With an idle timeout the backend is stopped once it has not been used for
+// that long, and started again by the next request.
+type lazyClient struct {
+	name        string
+	connect     func(ctx context.Context) (client.MCPClient, *manifest.Manifest, error)
+	idleTimeout time.Duration
+
+	// onStart is called each time the backend was started
+	onStart func(ctx context.Context, mcpClient client.MCPClient)
+
+	// keepAlive returns true while the backend must keep running although
+	// idle, for example to send the updates of subscribed resources
+	keepAlive func() bool
+
+	mu         sync.Mutex // protects the fields below
+	current    client.MCPClient
+	manifest   *manifest.Manifest
+	handlers   []func(notification mcp.JSONRPCNotification)
+	lost       []func(err error)
+	connecting chan struct{} // closed once the backend being started is, nil if none is
+	closes     int           // counts Close calls, to stop backends started meanwhile
+	inflight   int
+	idleGen    int
+}
+
+// get returns the client of the backend, starting the backend if needed.
+// The backend is in use until the returned release function is called.
+// It is started without holding the lock, requests arriving meanwhile wait
+// for it while listings are still answered from the manifest.
+func (c *lazyClient) get(ctx context.Context) (client.MCPClient, func(), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.current == nil {
+		if c.connecting != nil {
+			if err := c.wait(ctx, c.connecting); err != nil {
+				return nil, nil, err
+			}
+
+			continue
+		}
+
+		if err := c.start(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	c.inflight++
+	c.idleGen++
+
+	return c.current, c.release, nil
+}
+
+// wait waits for the backend being started by another request, releasing
+// the lock meanwhile. Called with the lock held.
+func (c *lazyClient) wait(ctx context.Context, connecting chan struct{}) error {
+	c.mu.Unlock()
+	defer c.mu.Lock()
+
+	select {
+	case <-connecting:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for backend %s to start: %w", c.name, ctx.Err())
+	}
+}
+
+// start starts the backend, releasing the lock meanwhile. Called with the
+// lock held.
+func (c *lazyClient) start(ctx context.Context) error {
+	connecting := make(chan struct{})
+	c.connecting = connecting
+	closes := c.closes
+
+	c.mu.Unlock()
+	mcpClient, captured, err := c.connect(ctx)
+	c.mu.Lock()
+
+	c.connecting = nil
+	close(connecting)
+
+	if err != nil {
+		return err
+	}
+
+	if c.closes != closes {
+		closeClient(c.name, mcpClient)
+
+		return fmt.Errorf("%w: %s", ErrBackendClosed, c.name)
+	}
+
+	for _, handler := range c.handlers {
+		mcpClient.OnNotification(handler)
+	}
+
+	if notifier, ok := mcpClient.(connectionLostNotifier); ok {
+		notifier.OnConnectionLost(func(err error) {
+			c.connectionLost(mcpClient, err)
+		})
+	}
+
+	c.current = mcpClient
+	c.manifest = captured
+
+	// Idle timers of the previous backend must not stop this one
+	c.idleGen++
+
+	if c.onStart != nil {
+		c.mu.Unlock()
+		c.onStart(ctx, mcpClient)
+		c.mu.Lock()
+	}
+
+	return nil
+}
+
+// connectionLost reports the lost connection of the running backend to the
+// handlers. Backends that were stopped or replaced meanwhile are not reported.
+func (c *lazyClient) connectionLost(lost client.MCPClient, err error) {
+	c.mu.Lock()
+	current, handlers := c.current, c.lost
+	c.mu.Unlock()
+
+	if current != lost {
+		return
+	}
+
+	for _, handler := range handlers {
+		handler(err)
+	}
+}
+
+// release marks a request to the backend as done, stopping the backend
+// after the idle timeout if no other request is running.
+func (c *lazyClient) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inflight--
+
+	if c.inflight > 0 || c.idleTimeout <= 0 {
+		return
+	}
+
+	gen := c.idleGen
+
+	time.AfterFunc(c.idleTimeout, func() {
+		c.idle(gen)
+	})
+}
+
+// idle stops the backend unless it was used since the timer started or must
+// be kept alive. Releasing the last request keeping it alive, like
+// unsubscribing, starts the timer again.
+func (c *lazyClient) idle(gen int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.idleGen != gen || c.inflight > 0 || c.current == nil {
+		return
+	}
+
+	if c.keepAlive != nil && c.keepAlive() {
+		return
+	}
+
+	log.Printf("Stopping backend %s after being idle for %s", c.name, c.idleTimeout)
+
+	closeClient(c.name, c.current)
+	c.current = nil
+}
+
+// started returns the client of the backend if it is running, and the
+// manifest to answer from otherwise.
+func (c *lazyClient) started() (client.MCPClient, *manifest.Manifest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.current, c.manifest
+}
+
+// Initialize implements the client.MCPClient interface. The backend is
+// already initialized when started, so this answers from the manifest.
+func (c *lazyClient) Initialize(
+	_ context.Context,
+	_ mcp.InitializeRequest,
+) (*mcp.InitializeResult, error) {
+	_, cached := c.started()
+	result := cached.Result
+
+	return &result, nil
+}
+
+// Ping implements the client.MCPClient interface.
+// A backend that has not been started is not pinged.
+func (c *lazyClient) Ping(ctx context.Context) error {
+	current, _ := c.started()
+	if current == nil {
+		return nil
+	}
+
+	return current.Ping(ctx) //nolint:wrapcheck // Transparent wrapper
+}
+
+// ListResourcesByPage implements the client.MCPClient interface.
+func (c *lazyClient) ListResourcesByPage(
+	ctx context.Context,
+	request mcp.ListResourcesRequest,
+) (*mcp.ListResourcesResult, error) {
+	current, cached := c.started()
+	if current == nil {
+		return &mcp.ListResourcesResult{Resources: cached.Resources}, nil
+	}
+
+	return current.ListResourcesByPage(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// ListResources implements the client.MCPClient interface.
+func (c *lazyClient) ListResources(
+	ctx context.Context,
+	request mcp.ListResourcesRequest,
+) (*mcp.ListResourcesResult, error) {
+	current, cached := c.started()
+	if current == nil {
+		return &mcp.ListResourcesResult{Resources: cached.Resources}, nil
+	}
+
+	return current.ListResources(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// ListResourceTemplatesByPage implements the client.MCPClient interface.
+func (c *lazyClient) ListResourceTemplatesByPage(
+	ctx context.Context,
+	request mcp.ListResourceTemplatesRequest,
+) (*mcp.ListResourceTemplatesResult, error) {
+	current, cached := c.started()
+	if current == nil {
+		return &mcp.ListResourceTemplatesResult{ResourceTemplates: cached.Templates}, nil
+	}
+
+	return current.ListResourceTemplatesByPage(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// ListResourceTemplates implements the client.MCPClient interface.
+func (c *lazyClient) ListResourceTemplates(
+	ctx context.Context,
+	request mcp.ListResourceTemplatesRequest,
+) (*mcp.ListResourceTemplatesResult, error) {
+	current, cached := c.started()
+	if current == nil {
+		return &mcp.ListResourceTemplatesResult{ResourceTemplates: cached.Templates}, nil
+	}
+
+	return current.ListResourceTemplates(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// ReadResource implements the client.MCPClient interface.
+func (c *lazyClient) ReadResource(
+	ctx context.Context,
+	request mcp.ReadResourceRequest,
+) (*mcp.ReadResourceResult, error) {
+	current, release, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	return current.ReadResource(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// Subscribe implements the client.MCPClient interface.
+func (c *lazyClient) Subscribe(ctx context.Context, request mcp.SubscribeRequest) error {
+	current, release, err := c.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	return current.Subscribe(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// Unsubscribe implements the client.MCPClient interface.
+func (c *lazyClient) Unsubscribe(ctx context.Context, request mcp.UnsubscribeRequest) error {
+	current, release, err := c.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	return current.Unsubscribe(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// ListPromptsByPage implements the client.MCPClient interface.
+func (c *lazyClient) ListPromptsByPage(
+	ctx context.Context,
+	request mcp.ListPromptsRequest,
+) (*mcp.ListPromptsResult, error) {
+	current, cached := c.started()
+	if current == nil {
+		return &mcp.ListPromptsResult{Prompts: cached.Prompts}, nil
+	}
+
+	return current.ListPromptsByPage(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// ListPrompts implements the client.MCPClient interface.
+func (c *lazyClient) ListPrompts(
+	ctx context.Context,
+	request mcp.ListPromptsRequest,
+) (*mcp.ListPromptsResult, error) {
+	current, cached := c.started()
+	if current == nil {
+		return &mcp.ListPromptsResult{Prompts: cached.Prompts}, nil
+	}
+
+	return current.ListPrompts(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// GetPrompt implements the client.MCPClient interface.
+func (c *lazyClient) GetPrompt(
+	ctx context.Context,
+	request mcp.GetPromptRequest,
+) (*mcp.GetPromptResult, error) {
+	current, release, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	return current.GetPrompt(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// ListToolsByPage implements the client.MCPClient interface.
+func (c *lazyClient) ListToolsByPage(
+	ctx context.Context,
+	request mcp.ListToolsRequest,
+) (*mcp.ListToolsResult, error) {
+	current, cached := c.started()
+	if current == nil {
+		return &mcp.ListToolsResult{Tools: cached.Tools}, nil
+	}
+
+	return current.ListToolsByPage(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// ListTools implements the client.MCPClient interface.
+func (c *lazyClient) ListTools(
+	ctx context.Context,
+	request mcp.ListToolsRequest,
+) (*mcp.ListToolsResult, error) {
+	current, cached := c.started()
+	if current == nil {
+		return &mcp.ListToolsResult{Tools: cached.Tools}, nil
+	}
+
+	return current.ListTools(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// CallTool implements the client.MCPClient interface.
+func (c *lazyClient) CallTool(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	current, release, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	return current.CallTool(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// SetLevel implements the client.MCPClient interface.
+func (c *lazyClient) SetLevel(ctx context.Context, request mcp.SetLevelRequest) error {
+	current, release, err := c.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	return current.SetLevel(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// Complete implements the client.MCPClient interface.
+func (c *lazyClient) Complete(
+	ctx context.Context,
+	request mcp.CompleteRequest,
+) (*mcp.CompleteResult, error) {
+	current, release, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	return current.Complete(ctx, request) //nolint:wrapcheck // Transparent wrapper
+}
+
+// Close implements the client.MCPClient interface. A later request starts
+// the backend again.
+func (c *lazyClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closes++
+
+	if c.current == nil {
+		return nil
+	}
+
+	err := c.current.Close()
+	c.current = nil
+
+	return err //nolint:wrapcheck // Transparent wrapper
+}
+
+// OnNotification implements the client.MCPClient interface. Handlers are
+// kept across restarts of the backend.
+func (c *lazyClient) OnNotification(handler func(notification mcp.JSONRPCNotification)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.handlers = append(c.handlers, handler)
+
+	if c.current != nil {
+		c.current.OnNotification(handler)
+	}
+}
+
+// OnConnectionLost implements the connectionLostNotifier interface. Handlers
+// are kept across restarts of the backend.
+func (c *lazyClient) OnConnectionLost(handler func(err error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lost = append(c.lost, handler)
+}
+
+// RootListChanges implements the rootsNotifier interface. A backend that has
+// not been started is notified once it is, see Interposer.lazyStarted.
+func (c *lazyClient) RootListChanges(ctx context.Context) error {
+	current, _ := c.started()
+
+	notifier, ok := current.(rootsNotifier)
+	if !ok {
+		return nil
+	}
+
+	return notifier.RootListChanges(ctx) //nolint:wrapcheck // Transparent wrapper
+}
+
+// connectLazy returns a client that starts the backend on demand. A lazy
+// backend is advertised from its cached manifest until first used, without a
+// current manifest or if it is only stopped when idle it is started right away.
+func (i *Interposer) connectLazy(
+	ctx context.Context,
+	name string,
+	cfg config.Server,
+) (client.MCPClient, *mcp.InitializeResult, error) {
+	lazy := &lazyClient{
+		name: name,
+		connect: func(ctx context.Context) (client.MCPClient, *manifest.Manifest, error) {
+			return i.connectCapturing(ctx, name, cfg)
+		},
+		idleTimeout: cfg.IdleTimeout.Duration(),
+		onStart: func(ctx context.Context, mcpClient client.MCPClient) {
+			i.lazyStarted(ctx, name, mcpClient)
+		},
+		keepAlive: func() bool {
+			return i.subscriptions.active(name)
+		},
+	}
+
+	if cfg.Lazy && i.manifests != nil {
+		cached, err := i.manifests.Load(cfg)
+		if err == nil {
+			log.Printf("Advertising %s from its manifest until it is used", name)
+
+			lazy.manifest = cached
+			result := cached.Result
+
+			return lazy, &result, nil
+		}
+
+		log.Printf("Starting lazy backend %s to capture its manifest: %v", name, err)
+	}
+
+	_, release, err := lazy.get(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Starts the idle timeout
+	release()
+
+	_, captured := lazy.started()
+	result := captured.Result
+
+	return lazy, &result, nil
+}
+
+// lazyStarted notifies a lazy backend that was started of the roots of the
+// clients, as root changes are not notified while it is stopped.
+func (i *Interposer) lazyStarted(ctx context.Context, name string, mcpClient client.MCPClient) {
+	if _, err := i.roots.only(); errors.Is(err, ErrRootsUnsupported) {
+		return
+	}
+
+	notifier, ok := mcpClient.(rootsNotifier)
+	if !ok {
+		return
+	}
+
+	if err := notifier.RootListChanges(ctx); err != nil {
+		log.Printf("Warning: failed to notify %s of root changes: %v", name, err)
+	}
+}
+
+// connectCapturing connects to a backend and captures its manifest,
+// caching it for the next run if the backend is lazy.
+func (i *Interposer) connectCapturing(
+	ctx context.Context,
+	name string,
+	cfg config.Server,
+) (client.MCPClient, *manifest.Manifest, error) {
+	log.Printf("Starting backend %s on demand", name)
+
+	mcpClient, result, err := i.connect(ctx, name, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	captured, err := manifest.Capture(ctx, cfg, mcpClient, result)
+	if err != nil {
+		closeClient(name, mcpClient)
+
+		return nil, nil, fmt.Errorf("failed to capture manifest of %s: %w", name, err)
+	}
+
+	if !cfg.Lazy || i.manifests == nil {
+		return mcpClient, captured, nil
+	}
+
+	if err := i.manifests.Save(cfg, captured); err != nil {
+		log.Printf("Warning: failed to save manifest of %s: %v", name, err)
+	}
+
+	return mcpClient, captured, nil
+}
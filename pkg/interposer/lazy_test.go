@@ -0,0 +1,311 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package interposer
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+	"github.com/jkoelker/posuer/pkg/manifest"
+)
+
+// countingClientFactory returns a factory counting the clients it created.
+func countingClientFactory(created *atomic.Int32) func(config.Server) (client.MCPClient, error) {
+	return func(_ config.Server) (client.MCPClient, error) {
+		created.Add(1)
+
+		return createMockClient(), nil
+	}
+}
+
+func newLazyInterposer(t *testing.T, store *manifest.Store, created *atomic.Int32) *Interposer {
+	t.Helper()
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(countingClientFactory(created)),
+		WithManifestStore(store),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	return interposerInstance
+}
+
+func TestLazyBackendStartsOnFirstUse(t *testing.T) {
+	t.Parallel()
+
+	store := manifest.NewStore(t.TempDir())
+	serverConfig := config.Server{
+		Name:    "test-server",
+		Type:    config.ServerTypeStdio,
+		Command: "test-server",
+		Lazy:    true,
+	}
+
+	// Without a manifest the backend is started to capture one
+	var created atomic.Int32
+
+	first := newLazyInterposer(t, store, &created)
+	require.NoError(t, first.AddBackend(context.Background(), "test-server", serverConfig))
+	assert.Equal(t, int32(1), created.Load())
+
+	_, err := store.Load(serverConfig)
+	require.NoError(t, err)
+
+	// With a manifest the capabilities are advertised without starting it
+	created.Store(0)
+
+	second := newLazyInterposer(t, store, &created)
+	require.NoError(t, second.AddBackend(context.Background(), "test-server", serverConfig))
+	assert.Equal(t, int32(0), created.Load())
+
+	assert.NotNil(t, second.Server().GetTool("test-server-test-tool"))
+	assert.Len(t, second.registry.GetCapabilitiesForBackend("test-server")["prompt"], 1)
+	assert.Len(t, second.registry.GetCapabilitiesForBackend("test-server")["resource"], 1)
+	assert.Len(t, second.registry.GetCapabilitiesForBackend("test-server")["template"], 1)
+
+	// The first call starts it, later calls reuse it
+	require.NoError(t, callTool(t, second, "test-server-test-tool"))
+	assert.Equal(t, int32(1), created.Load())
+
+	require.NoError(t, callTool(t, second, "test-server-test-tool"))
+	assert.Equal(t, int32(1), created.Load())
+}
+
+func TestLazyBackendStaleManifest(t *testing.T) {
+	t.Parallel()
+
+	store := manifest.NewStore(t.TempDir())
+	serverConfig := config.Server{
+		Name:    "test-server",
+		Type:    config.ServerTypeStdio,
+		Command: "test-server",
+		Lazy:    true,
+	}
+
+	require.NoError(t, store.Save(serverConfig, &manifest.Manifest{Key: manifest.Key(serverConfig)}))
+
+	// A manifest of a server launched differently is not used
+	serverConfig.Args = []string{"--other"}
+
+	var created atomic.Int32
+
+	interposerInstance := newLazyInterposer(t, store, &created)
+	require.NoError(t, interposerInstance.AddBackend(context.Background(), "test-server", serverConfig))
+
+	assert.Equal(t, int32(1), created.Load())
+	assert.NotNil(t, interposerInstance.Server().GetTool("test-server-test-tool"))
+}
+
+// closeCountingMCPClient is a mock client counting how often it was closed.
+type closeCountingMCPClient struct {
+	*MockMCPClient
+
+	closed *atomic.Int32
+}
+
+// Close implements the Close method of the MCPClient interface.
+func (m *closeCountingMCPClient) Close() error {
+	m.closed.Add(1)
+
+	return nil
+}
+
+func TestIdleBackendStopsAndRestarts(t *testing.T) {
+	t.Parallel()
+
+	var created, closed atomic.Int32
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(func(_ config.Server) (client.MCPClient, error) {
+			created.Add(1)
+
+			return &closeCountingMCPClient{MockMCPClient: createMockClient(), closed: &closed}, nil
+		}),
+		WithManifestStore(nil),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	serverConfig := config.Server{
+		Name:        "test-server",
+		Type:        config.ServerTypeStdio,
+		IdleTimeout: config.Duration(10 * time.Millisecond),
+	}
+
+	require.NoError(t, interposerInstance.AddBackend(context.Background(), "test-server", serverConfig))
+	assert.Equal(t, int32(1), created.Load())
+
+	// The unused backend is stopped but its tools stay advertised
+	require.Eventually(t, func() bool {
+		return closed.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	assert.True(t, interposerInstance.verifyClientExists("test-server"))
+	assert.NotNil(t, interposerInstance.Server().GetTool("test-server-test-tool"))
+
+	// The next call starts it again
+	require.NoError(t, callTool(t, interposerInstance, "test-server-test-tool"))
+	assert.Equal(t, int32(2), created.Load())
+
+	require.Eventually(t, func() bool {
+		return closed.Load() == 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestLazyBackendStartsOutsideLock(t *testing.T) {
+	t.Parallel()
+
+	var connects atomic.Int32
+
+	unblock := make(chan struct{})
+	lazy := &lazyClient{
+		name: "test-server",
+		connect: func(_ context.Context) (client.MCPClient, *manifest.Manifest, error) {
+			connects.Add(1)
+			<-unblock
+
+			return createMockClient(), &manifest.Manifest{}, nil
+		},
+		manifest: &manifest.Manifest{Tools: []mcp.Tool{mcp.NewTool("cached-tool")}},
+	}
+
+	results := make(chan error, 2)
+
+	for range 2 {
+		go func() {
+			_, release, err := lazy.get(context.Background())
+			if err == nil {
+				release()
+			}
+
+			results <- err
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		return connects.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	// Listings are answered from the manifest while the backend starts
+	tools, err := lazy.ListTools(context.Background(), mcp.ListToolsRequest{})
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+	assert.Equal(t, "cached-tool", tools.Tools[0].Name)
+
+	// Requests waiting for the backend give up with their context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err = lazy.get(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	close(unblock)
+
+	require.NoError(t, <-results)
+	require.NoError(t, <-results)
+	assert.Equal(t, int32(1), connects.Load())
+}
+
+// respawnedClient is a mock client counting the root changes it is notified
+// of, whose connection can be lost.
+type respawnedClient struct {
+	*rootsClient
+
+	lost chan func(error)
+}
+
+// OnConnectionLost implements the connectionLostNotifier interface.
+func (c *respawnedClient) OnConnectionLost(handler func(error)) {
+	c.lost <- handler
+}
+
+func TestIdleBackendRespawnKeepsHandlers(t *testing.T) {
+	t.Parallel()
+
+	clients := make(chan *respawnedClient, 10)
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(func(_ config.Server) (client.MCPClient, error) {
+			mcpClient := &respawnedClient{
+				rootsClient: &rootsClient{MockMCPClient: createMockClient()},
+				lost:        make(chan func(error), 1),
+			}
+			clients <- mcpClient
+
+			return mcpClient, nil
+		}),
+		WithManifestStore(nil),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	// A client declaring roots is connected
+	session := &rootsSession{samplingSession: &samplingSession{fakeSession: newFakeSession("first")}}
+
+	interposerInstance.roots.mu.Lock()
+	interposerInstance.roots.sessions = append(interposerInstance.roots.sessions, session)
+	interposerInstance.roots.mu.Unlock()
+
+	serverConfig := config.Server{
+		Name:        "test-server",
+		Type:        config.ServerTypeStdio,
+		IdleTimeout: config.Duration(250 * time.Millisecond),
+		Backoff:     &config.Backoff{Initial: config.Duration(time.Millisecond)},
+	}
+
+	require.NoError(t, interposerInstance.AddBackend(context.Background(), "test-server", serverConfig))
+
+	first := <-clients
+	assert.Equal(t, int32(1), first.changes.Load())
+
+	interposerInstance.mu.RLock()
+	supervised, ok := interposerInstance.clients["test-server"].(*supervisedClient)
+	interposerInstance.mu.RUnlock()
+	require.True(t, ok)
+
+	lazy, ok := supervised.MCPClient.(*lazyClient)
+	require.True(t, ok)
+
+	// Stopped when idle, the backend is not reported lost
+	require.Eventually(t, func() bool {
+		current, _ := lazy.started()
+
+		return current == nil
+	}, 2*time.Second, time.Millisecond)
+
+	(<-first.lost)(transport.ErrTransportClosed)
+	assert.True(t, interposerInstance.verifyClientExists("test-server"))
+
+	// The respawned backend is told about the roots again
+	require.NoError(t, callTool(t, interposerInstance, "test-server-test-tool"))
+
+	second := <-clients
+	assert.Equal(t, int32(1), second.changes.Load())
+
+	// Losing it is detected by the supervisor, which reconnects
+	(<-second.lost)(transport.ErrTransportClosed)
+
+	select {
+	case <-clients:
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "the lost backend should be reconnected")
+	}
+}
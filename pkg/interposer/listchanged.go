@@ -0,0 +1,224 @@
+package interposer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// watchListChanges refreshes the capabilities of a backend when it reports
+// that its tools, prompts or resources changed.
+func (i *Interposer) watchListChanges(name string, mcpClient client.MCPClient) {
+	mcpClient.OnNotification(func(notification mcp.JSONRPCNotification) {
+		switch notification.Method {
+		case mcp.MethodNotificationToolsListChanged,
+			mcp.MethodNotificationPromptsListChanged,
+			mcp.MethodNotificationResourcesListChanged:
+		default:
+			return
+		}
+
+		log.Printf("Backend %s sent %s", name, notification.Method)
+
+		// The transport reads responses only after the handler returns, so
+		// listing from within it would deadlock
+		go i.refreshBackend(name, mcpClient, notification.Method)
+	})
+}
+
+// refreshBackend re-lists the changed capabilities of a backend, updates
+// the registered capabilities to match and notifies clients.
+func (i *Interposer) refreshBackend(name string, mcpClient client.MCPClient, method string) {
+	i.lifecycle.Lock()
+	defer i.lifecycle.Unlock()
+
+	// The backend was replaced or removed in the meantime
+	if !i.isCurrentClient(name, mcpClient) {
+		return
+	}
+
+	i.mu.RLock()
+	cfg := i.configs[name]
+	i.mu.RUnlock()
+
+	var (
+		changes capabilityChanges
+		err     error
+	)
+
+	switch method {
+	case mcp.MethodNotificationToolsListChanged:
+		changes.toolsChanged, err = i.syncTools(name, mcpClient, cfg)
+
+	case mcp.MethodNotificationPromptsListChanged:
+		changes.promptsChanged, err = syncItems(
+			i.ctx, i, cfg, config.CapabilityTypePrompt, "prompt",
+			listPrompts(mcpClient, cfg.Timeouts.List),
+			func(prompt mcp.Prompt) server.PromptHandlerFunc {
+				return handlePrompt(cfg, prompt, mcpClient)
+			},
+		)
+
+	case mcp.MethodNotificationResourcesListChanged:
+		changes.resourcesChanged, err = syncItems(
+			i.ctx, i, cfg, config.CapabilityTypeResource, "resource",
+			listResources(mcpClient, cfg.Timeouts.List),
+			func(resource mcp.Resource) server.ResourceHandlerFunc {
+				return handleResource(cfg, resource, mcpClient)
+			},
+		)
+		if err != nil {
+			break
+		}
+
+		changes.templatesChanged, err = syncItems(
+			i.ctx, i, cfg, config.CapabilityTypeTemplate, "template",
+			listResourceTemplates(mcpClient, cfg.Timeouts.List),
+			func(template mcp.ResourceTemplate) server.ResourceTemplateHandlerFunc {
+				return handleResource(cfg, template, mcpClient)
+			},
+		)
+	}
+
+	if err != nil {
+		log.Printf("Warning: failed to refresh %s: %v", name, err)
+	}
+
+	i.sendNotifications(
+		changes.toolsChanged,
+		changes.promptsChanged,
+		changes.resourcesChanged,
+		changes.templatesChanged,
+	)
+}
+
+// syncTools makes the registered tools of a backend match the tools it lists.
+func (i *Interposer) syncTools(name string, mcpClient client.MCPClient, cfg config.Server) (bool, error) {
+	return syncItems(
+		i.ctx, i, cfg, config.CapabilityTypeTool, "tool",
+		listTools(mcpClient, cfg.Timeouts.List),
+		func(tool mcp.Tool) server.ToolHandlerFunc {
+			return handleTool(cfg, tool, mcpClient, i.toolAllowed(name, tool))
+		},
+	)
+}
+
+// syncItems makes the registered items of a type match the items a backend
+// lists, registering new and changed items and deleting removed ones.
+// Returns true if anything changed.
+func syncItems[Item Items, Handler Handlers](
+	ctx context.Context,
+	interposer *Interposer,
+	cfg config.Server,
+	capability config.CapabilityType,
+	capType string,
+	request func(ctx context.Context, cursor string) ([]Item, string, error),
+	create func(Item) Handler,
+) (bool, error) {
+	items, err := listAllItems(ctx, enabledItems(interposer, cfg, capability, request))
+	if err != nil {
+		return false, err
+	}
+
+	// Whatever is left once the listed items are matched was removed. Maps
+	// the name the item is exposed under to the name it is registered under,
+	// which differ for suffixed items
+	stale := make(map[string]string)
+	for _, capName := range interposer.registry.GetCapabilitiesForBackend(cfg.Name)[capType] {
+		stale[interposer.registry.GetRequestedName(capType, capName)] = capName
+	}
+
+	changed := false
+
+	for _, item := range items {
+		transformed, err := transform(cfg, item)
+		if err != nil {
+			// Left stale, so that a previously valid item is removed
+			log.Printf("Skipping %s %s from %s: %v", capability, itemName(item), cfg.Name, err)
+
+			continue
+		}
+
+		capName := itemName(transformed)
+
+		if registered, exists := stale[capName]; exists {
+			delete(stale, capName)
+
+			if interposer.isRegistered(capType, registered, capName, transformed) {
+				continue
+			}
+
+			// Resources and templates are keyed by URI, so a changed one
+			// is replaced rather than overwritten
+			interposer.deleteItems(capType, []string{registered})
+		}
+
+		log.Printf("Updating %s %s from %s", capability, capName, cfg.Name)
+
+		if err := register(interposer, cfg.Name, transformed, create(item)); err != nil {
+			log.Printf("Failed to register item %s: %v", capName, err)
+
+			continue
+		}
+
+		// The collision policy may have skipped the item
+		changed = changed || interposer.registry.Provides(cfg.Name, capType, capName)
+	}
+
+	removed := make([]string, 0, len(stale))
+	for _, capName := range stale {
+		log.Printf("Removing %s %s from %s", capability, capName, cfg.Name)
+
+		interposer.registry.RemoveCapability(capType, capName)
+		removed = append(removed, capName)
+	}
+
+	interposer.deleteItems(capType, removed)
+
+	return changed || len(removed) > 0, nil
+}
+
+// isRegistered returns true if the item is registered exactly as given, under
+// the registered name rather than its own if it was suffixed.
+func (i *Interposer) isRegistered(capType, registeredName, capName string, item any) bool {
+	registered, exists := i.catalog.get(capType, registeredName)
+	if !exists {
+		return false
+	}
+
+	var definition any
+
+	switch value := registered.(type) {
+	case server.ServerTool:
+		value.Tool.Name = capName
+		definition = value.Tool
+	case server.ServerPrompt:
+		value.Prompt.Name = capName
+		definition = value.Prompt
+	case server.ServerResource:
+		value.Resource.Name = capName
+		definition = value.Resource
+	case server.ServerResourceTemplate:
+		value.Template.Name = capName
+		definition = value.Template
+	}
+
+	expected, err := json.Marshal(item)
+	if err != nil {
+		return false
+	}
+
+	actual, err := json.Marshal(definition)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(expected, actual)
+}
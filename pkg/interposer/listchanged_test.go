@@ -0,0 +1,133 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package interposer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// changingMCPClient is a mock client whose tools change at runtime.
+type changingMCPClient struct {
+	*MockMCPClient
+
+	mu       sync.Mutex
+	current  []mcp.Tool
+	handlers []func(notification mcp.JSONRPCNotification)
+}
+
+// ListTools implements the ListTools method of the MCPClient interface.
+func (m *changingMCPClient) ListTools(
+	_ context.Context,
+	_ mcp.ListToolsRequest,
+) (*mcp.ListToolsResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return &mcp.ListToolsResult{Tools: m.current}, nil
+}
+
+// OnNotification implements the OnNotification method of the MCPClient interface.
+func (m *changingMCPClient) OnNotification(handler func(notification mcp.JSONRPCNotification)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.handlers = append(m.handlers, handler)
+}
+
+// setTools changes the tools and notifies the handlers like a backend would.
+func (m *changingMCPClient) setTools(tools ...mcp.Tool) {
+	m.mu.Lock()
+	m.current = tools
+	handlers := m.handlers
+	m.mu.Unlock()
+
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: mcp.MethodNotificationToolsListChanged,
+		},
+	}
+
+	for _, handler := range handlers {
+		handler(notification)
+	}
+}
+
+func TestBackendListChanged(t *testing.T) {
+	t.Parallel()
+
+	mockClient := &changingMCPClient{MockMCPClient: createMockClient()}
+	mockClient.current = mockClient.tools
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(func(_ config.Server) (client.MCPClient, error) {
+			return mockClient, nil
+		}),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	serverConfig := config.Server{
+		Name: "test-server",
+		Type: config.ServerTypeStdio,
+		Disable: &config.Capability{
+			Capabilities: map[config.CapabilityType][]string{
+				config.CapabilityTypeTool: {"hidden"},
+			},
+		},
+	}
+
+	require.NoError(t, interposerInstance.AddBackend(context.Background(), "test-server", serverConfig))
+	require.NotNil(t, interposerInstance.Server().GetTool("test-server-test-tool"))
+
+	session := newFakeSession("session")
+	require.NoError(t, interposerInstance.Server().RegisterSession(context.Background(), session))
+
+	// The backend changes a tool and adds two, one of them disabled
+	mockClient.setTools(
+		mcp.NewTool("new-tool", mcp.WithDescription("A new tool")),
+		mcp.NewTool("hidden", mcp.WithDescription("A disabled tool")),
+		mcp.NewTool("test-tool", mcp.WithDescription("A changed tool")),
+	)
+
+	require.Eventually(t, func() bool {
+		tool := interposerInstance.Server().GetTool("test-server-test-tool")
+
+		return tool != nil && tool.Tool.Description == "A changed tool"
+	}, time.Second, time.Millisecond)
+
+	assert.NotNil(t, interposerInstance.Server().GetTool("test-server-new-tool"))
+	assert.Nil(t, interposerInstance.Server().GetTool("test-server-hidden"))
+
+	backend, exists := interposerInstance.registry.GetBackendForCapability("tool", "test-server-new-tool")
+	assert.True(t, exists)
+	assert.Equal(t, "test-server", backend)
+
+	// Calls to the new tool reach the backend
+	require.NoError(t, callTool(t, interposerInstance, "test-server-new-tool"))
+
+	// The backend drops everything but the new tool
+	mockClient.setTools(mcp.NewTool("new-tool", mcp.WithDescription("A new tool")))
+
+	require.Eventually(t, func() bool {
+		return interposerInstance.Server().GetTool("test-server-test-tool") == nil
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(t, []string{"test-server-new-tool"},
+		interposerInstance.registry.GetCapabilitiesForBackend("test-server")["tool"])
+
+	// Clients were told the tools changed
+	assert.Contains(t, session.methods(), mcp.MethodNotificationToolsListChanged)
+}
@@ -0,0 +1,242 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package interposer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+func TestNaming(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(mockClientFactory()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	empty := ""
+	serverConfig := config.Server{
+		Name:    "test-server",
+		Type:    config.ServerTypeStdio,
+		Prefix:  &empty,
+		Aliases: map[string]string{"test-prompt": "prompt"},
+	}
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+
+	assert.NotNil(t, interposerInstance.Server().GetTool("test-tool"))
+	assert.ElementsMatch(t, []string{"prompt", "Test Resource", "Test Template"}, listedNames(t, interposerInstance))
+
+	// Templates are read with the URI the backend knows
+	message := interposerInstance.Server().HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "resources/read",
+		"params": {"uri": "test://42"}
+	}`))
+
+	response, ok := message.(mcp.JSONRPCResponse)
+	require.True(t, ok, "reading should succeed, got %#v", message)
+	assert.NotNil(t, response.Result)
+
+	// Filters still use the backend's own names
+	serverConfig.Disable = &config.Capability{
+		Capabilities: map[config.CapabilityType][]string{
+			config.CapabilityTypePrompt: {"test-prompt"},
+		},
+	}
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{serverConfig}))
+
+	assert.ElementsMatch(t, []string{"Test Resource", "Test Template"}, listedNames(t, interposerInstance))
+}
+
+func TestNamingCollision(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(mockClientFactory()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	shared := "shared"
+
+	require.NoError(t, interposerInstance.AddBackend(context.Background(), "first", config.Server{
+		Name:   "first",
+		Prefix: &shared,
+	}))
+	require.NoError(t, interposerInstance.AddBackend(context.Background(), "second", config.Server{
+		Name:   "second",
+		Prefix: &shared,
+	}))
+
+	// The first registration is kept rather than overwritten
+	backend, exists := interposerInstance.registry.GetBackendForCapability("tool", "shared-test-tool")
+	require.True(t, exists)
+	assert.Equal(t, "first", backend)
+	assert.Empty(t, interposerInstance.registry.GetCapabilitiesForBackend("second"))
+
+	err = interposerInstance.RegisterTool("second", mcp.NewTool("shared-test-tool"), nil)
+	require.ErrorIs(t, err, ErrCapabilityCollision)
+}
+
+func TestConcurrentCollision(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(mockClientFactory()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	// Backends starting at the same time race to register the same tool
+	const backends = 20
+
+	var (
+		wait       sync.WaitGroup
+		registered atomic.Int32
+	)
+
+	start := make(chan struct{})
+
+	for index := range backends {
+		wait.Add(1)
+
+		go func() {
+			defer wait.Done()
+
+			<-start
+
+			backend := fmt.Sprintf("backend-%d", index)
+			if interposerInstance.RegisterTool(backend, mcp.NewTool("shared-tool"), nil) == nil {
+				registered.Add(1)
+			}
+		}()
+	}
+
+	close(start)
+	wait.Wait()
+
+	// Only one of them may own it, the others collide with it
+	assert.Equal(t, int32(1), registered.Load())
+	assert.Len(t, interposerInstance.Registry().GetCollisions(), backends-1)
+
+	owner, exists := interposerInstance.registry.GetBackendForCapability("tool", "shared-tool")
+	require.True(t, exists)
+
+	for _, collision := range interposerInstance.Registry().GetCollisions() {
+		assert.Equal(t, owner, collision.Owner)
+	}
+}
+
+func TestCollisionPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		policy CollisionPolicy
+		tools  map[string]string
+	}{
+		{
+			policy: CollisionPolicyFirstWins,
+			tools:  map[string]string{"shared-test-tool": "first"},
+		},
+		{
+			policy: CollisionPolicyLastWins,
+			tools:  map[string]string{"shared-test-tool": "second"},
+		},
+		{
+			policy: CollisionPolicyAutoSuffix,
+			tools:  map[string]string{"shared-test-tool": "first", "shared-test-tool-2": "second"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.policy), func(t *testing.T) {
+			t.Parallel()
+
+			interposerInstance, err := NewInterposer(
+				"TestInterposer",
+				"1.0.0",
+				WithClientFactory(mockClientFactory()),
+				WithCollisionPolicy(test.policy),
+			)
+			require.NoError(t, err)
+
+			t.Cleanup(func() { _ = interposerInstance.Close() })
+
+			shared := "shared"
+			configs := []config.Server{
+				{Name: "first", Prefix: &shared},
+				{Name: "second", Prefix: &shared},
+			}
+
+			for _, cfg := range configs {
+				require.NoError(t, interposerInstance.AddBackend(context.Background(), cfg.Name, cfg))
+			}
+
+			tools := make(map[string]string)
+			for backend, names := range interposerInstance.Registry().GetCapabilitiesByType("tool") {
+				for _, name := range names {
+					tools[name] = backend
+				}
+			}
+
+			assert.Equal(t, test.tools, tools)
+			assert.Len(t, interposerInstance.Server().ListTools(), len(test.tools))
+			assert.NotEmpty(t, interposerInstance.Registry().GetCollisionsForBackend("second"))
+
+			// Refreshing the backend keeps what the policy resolved
+			interposerInstance.mu.RLock()
+			mcpClient := interposerInstance.clients["second"]
+			interposerInstance.mu.RUnlock()
+
+			changed, err := interposerInstance.syncTools("second", mcpClient, configs[1])
+			require.NoError(t, err)
+			assert.False(t, changed)
+		})
+	}
+}
+
+func TestNamingInvalidTemplate(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(mockClientFactory()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	// The server's name makes the exposed template malformed, so the
+	// template is skipped while the other items are still registered
+	require.NotPanics(t, func() {
+		require.NoError(t, interposerInstance.AddBackend(context.Background(), "bad{", config.Server{
+			Name: "bad{",
+		}))
+	})
+
+	assert.NotNil(t, interposerInstance.Server().GetTool("bad{-test-tool"))
+	assert.ElementsMatch(t, []string{"bad{.test-prompt", "bad{-Test Resource"}, listedNames(t, interposerInstance))
+}
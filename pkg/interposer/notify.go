@@ -0,0 +1,119 @@
+package interposer
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// listChangedMethods are the notifications of changed capability lists,
+// in the order they are sent.
+var listChangedMethods = []string{
+	mcp.MethodNotificationToolsListChanged,
+	mcp.MethodNotificationPromptsListChanged,
+	mcp.MethodNotificationResourcesListChanged,
+}
+
+// notifier sends list_changed notifications to all clients. While held,
+// notifications are coalesced and sent once released.
+type notifier struct {
+	send func(method string)
+
+	mu      sync.Mutex // protects the fields below
+	holds   int
+	pending map[string]bool
+}
+
+// newNotifier creates a notifier sending notifications with send.
+func newNotifier(send func(method string)) *notifier {
+	return &notifier{
+		send:    send,
+		pending: make(map[string]bool),
+	}
+}
+
+// hold defers sending notifications until the matching release.
+func (n *notifier) hold() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.holds++
+}
+
+// release sends the deferred notifications, once per method, when the
+// last hold is released.
+func (n *notifier) release() {
+	n.mu.Lock()
+
+	n.holds--
+	if n.holds > 0 {
+		n.mu.Unlock()
+
+		return
+	}
+
+	pending := n.pending
+	n.pending = make(map[string]bool)
+	n.mu.Unlock()
+
+	for _, method := range listChangedMethods {
+		if pending[method] {
+			n.send(method)
+		}
+	}
+}
+
+// changed sends the notification, or defers it while held.
+func (n *notifier) changed(method string) {
+	n.mu.Lock()
+
+	if n.holds > 0 {
+		n.pending[method] = true
+		n.mu.Unlock()
+
+		return
+	}
+
+	n.mu.Unlock()
+
+	n.send(method)
+}
+
+// notifyAll sends a list_changed notification to all clients.
+func (i *Interposer) notifyAll(method string) {
+	log.Printf("Sending %s", method)
+	i.server.SendNotificationToAllClients(method, nil)
+}
+
+// notifyBackendCapabilities notifies clients of the capabilities a backend provides.
+func (i *Interposer) notifyBackendCapabilities(name string) {
+	i.sendNotifications(checkCapabilityChanges(i.registry.GetCapabilitiesForBackend(name)))
+}
+
+// advertiseListChanged advertises the list_changed notifications the
+// interposer sends. The server is built without them, as it would otherwise
+// send a notification for every single capability added or removed.
+func advertiseListChanged(_ context.Context, _ any, _ *mcp.InitializeRequest, result *mcp.InitializeResult) {
+	if result.Capabilities.Tools != nil {
+		result.Capabilities.Tools.ListChanged = true
+	}
+
+	if result.Capabilities.Prompts != nil {
+		result.Capabilities.Prompts.ListChanged = true
+	}
+
+	if result.Capabilities.Resources != nil {
+		result.Capabilities.Resources.ListChanged = true
+	}
+}
+
+// notificationHooks returns the server hooks advertising list_changed.
+func notificationHooks() *server.Hooks {
+	hooks := &server.Hooks{}
+	hooks.AddAfterInitialize(advertiseListChanged)
+
+	return hooks
+}
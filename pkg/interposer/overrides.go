@@ -0,0 +1,188 @@
+package interposer
+
+import (
+	"encoding/json"
+	"log"
+	"maps"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// overrideTool returns the tool with the server's override applied, which
+// replaces or appends to its description, sets its annotation hints and
+// patches its input schema.
+func overrideTool(cfg config.Server, tool mcp.Tool) mcp.Tool {
+	override, exists := cfg.ToolOverride(tool.Name)
+	if !exists {
+		return tool
+	}
+
+	if override.Description != nil {
+		tool.Description = *override.Description
+	}
+
+	if override.AppendDescription != "" {
+		if tool.Description != "" {
+			tool.Description += "\n\n"
+		}
+
+		tool.Description += override.AppendDescription
+	}
+
+	for hint, value := range override.Annotations {
+		setAnnotation(&tool.Annotations, hint, value)
+	}
+
+	if len(override.Parameters) > 0 {
+		tool.InputSchema = overrideSchema(cfg.Name, tool, override.Parameters)
+		tool.RawInputSchema = nil
+	}
+
+	return tool
+}
+
+// setAnnotation sets an annotation hint of a tool.
+func setAnnotation(annotations *mcp.ToolAnnotation, hint string, value bool) {
+	switch hint {
+	case config.AnnotationReadOnly:
+		annotations.ReadOnlyHint = &value
+	case config.AnnotationDestructive:
+		annotations.DestructiveHint = &value
+	case config.AnnotationIdempotent:
+		annotations.IdempotentHint = &value
+	case config.AnnotationOpenWorld:
+		annotations.OpenWorldHint = &value
+	}
+}
+
+// overrideSchema returns the input schema of the tool with its parameters
+// hidden, renamed or constrained. The tool's own schema is left untouched.
+func overrideSchema(
+	backend string,
+	tool mcp.Tool,
+	parameters map[string]config.ParameterOverride,
+) mcp.ToolInputSchema {
+	schema := tool.InputSchema
+
+	// Tools may carry their schema as raw JSON instead
+	if tool.RawInputSchema != nil {
+		schema = mcp.ToolInputSchema{}
+		if err := json.Unmarshal(tool.RawInputSchema, &schema); err != nil {
+			log.Printf("Warning: failed to parse the input schema of %s from %s: %v", tool.Name, backend, err)
+
+			return tool.InputSchema
+		}
+	}
+
+	// Parameters are looked up in the tool's own schema, since a parameter
+	// may be renamed to the name of another one
+	properties, required := schema.Properties, schema.Required
+
+	schema.Properties = maps.Clone(properties)
+	schema.Required = nil
+
+	for name := range parameters {
+		delete(schema.Properties, name)
+	}
+
+	for name, parameter := range parameters {
+		property, exists := properties[name]
+
+		switch {
+		case !exists:
+			log.Printf("Warning: override of %s from %s patches unknown parameter %s", tool.Name, backend, name)
+		case parameter.Hide:
+			// Hidden parameters are left out
+		case parameter.Rename != "":
+			schema.Properties[parameter.Rename] = overrideProperty(property, parameter)
+		default:
+			schema.Properties[name] = overrideProperty(property, parameter)
+		}
+	}
+
+	for _, name := range required {
+		parameter := parameters[name]
+
+		switch {
+		case parameter.Hide:
+			// Hidden parameters are no longer required, their value is
+			// added by restoreArguments if configured
+		case parameter.Rename != "":
+			schema.Required = append(schema.Required, parameter.Rename)
+		default:
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+// overrideProperty returns the schema of a parameter with its description
+// and enum replaced.
+func overrideProperty(property any, parameter config.ParameterOverride) any {
+	if parameter.Description == nil && parameter.Enum == nil {
+		return property
+	}
+
+	definition, ok := property.(map[string]any)
+	if !ok {
+		return property
+	}
+
+	definition = maps.Clone(definition)
+
+	if parameter.Description != nil {
+		definition["description"] = *parameter.Description
+	}
+
+	if parameter.Enum != nil {
+		definition["enum"] = parameter.Enum
+	}
+
+	return definition
+}
+
+// restoreArguments returns the arguments of a call to an overridden tool as
+// the backend expects them, with renamed parameters under their own name,
+// hidden parameters dropped and the values of hidden parameters added.
+func restoreArguments(override config.Override, arguments map[string]any) map[string]any {
+	if len(override.Parameters) == 0 {
+		return arguments
+	}
+
+	// Maps the names parameters are exposed under to their own names
+	renamed := make(map[string]string)
+
+	for name, parameter := range override.Parameters {
+		if parameter.Rename != "" {
+			renamed[parameter.Rename] = name
+		}
+	}
+
+	restored := make(map[string]any, len(arguments))
+
+	for name, value := range arguments {
+		if original, exists := renamed[name]; exists {
+			restored[original] = value
+
+			continue
+		}
+
+		// Parameters that are not exposed under their own name
+		if parameter, exists := override.Parameters[name]; exists && (parameter.Hide || parameter.Rename != "") {
+			continue
+		}
+
+		restored[name] = value
+	}
+
+	for name, parameter := range override.Parameters {
+		if parameter.Hide && parameter.Value != nil {
+			restored[name] = parameter.Value
+		}
+	}
+
+	return restored
+}
@@ -0,0 +1,103 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package interposer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// recordingClient records the arguments of the last tool call.
+type recordingClient struct {
+	*MockMCPClient
+
+	arguments any
+}
+
+func (c *recordingClient) CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	c.arguments = request.Params.Arguments
+
+	return c.MockMCPClient.CallTool(ctx, request)
+}
+
+func TestToolOverrides(t *testing.T) {
+	t.Parallel()
+
+	var cfg config.Server
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: github
+overrides:
+  search:
+    append_description: Prefer the issues tool for issues.
+    annotations:
+      readOnlyHint: true
+    parameters:
+      owner:
+        hide: true
+        value: jkoelker
+      q:
+        rename: query
+        description: What to search for
+      sort:
+        enum: [stars, updated]
+`), &cfg))
+
+	tool := mcp.NewTool(
+		"search",
+		mcp.WithDescription("Searches GitHub."),
+		mcp.WithString("owner", mcp.Required()),
+		mcp.WithString("q", mcp.Required()),
+		mcp.WithString("sort"),
+	)
+
+	overridden := overrideTool(cfg, tool)
+
+	assert.Equal(t, "Searches GitHub.\n\nPrefer the issues tool for issues.", overridden.Description)
+	assert.True(t, toolAnnotations(overridden)[config.AnnotationReadOnly])
+	assert.ElementsMatch(t, []string{"query"}, overridden.InputSchema.Required)
+	assert.Equal(t, map[string]any{
+		"query": map[string]any{"type": "string", "description": "What to search for"},
+		"sort":  map[string]any{"type": "string", "enum": []any{"stars", "updated"}},
+	}, overridden.InputSchema.Properties)
+
+	// The backend's own tool is left untouched
+	assert.Contains(t, tool.InputSchema.Properties, "owner")
+	assert.Equal(t, map[string]any{"type": "string"}, tool.InputSchema.Properties["q"])
+
+	t.Run("call", func(t *testing.T) {
+		t.Parallel()
+
+		mcpClient := &recordingClient{MockMCPClient: &MockMCPClient{}}
+		handler := handleTool(cfg, tool, mcpClient, func() bool { return true })
+
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "github-search"
+		request.Params.Arguments = map[string]any{"query": "posuer", "sort": "stars", "owner": "someone"}
+
+		_, err := handler(context.Background(), request)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]any{"q": "posuer", "sort": "stars", "owner": "jkoelker"}, mcpClient.arguments)
+	})
+
+	t.Run("swapped names", func(t *testing.T) {
+		t.Parallel()
+
+		override := config.Override{Parameters: map[string]config.ParameterOverride{
+			"owner": {Rename: "q"},
+			"q":     {Rename: "owner"},
+		}}
+
+		assert.Equal(
+			t,
+			map[string]any{"owner": "a", "q": "b"},
+			restoreArguments(override, map[string]any{"q": "a", "owner": "b"}),
+		)
+	})
+}
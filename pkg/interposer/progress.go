@@ -0,0 +1,117 @@
+package interposer
+
+import (
+	"context"
+	"log"
+	"maps"
+	"strconv"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// methodNotificationProgress reports the progress of a request.
+const methodNotificationProgress = "notifications/progress"
+
+// progressTarget is the client request a backend reports progress on.
+type progressTarget struct {
+	sessionID string
+	token     mcp.ProgressToken
+}
+
+// progress routes the progress notifications of backends to the clients
+// whose tool calls they report on. Backends are given a token of their own
+// for each call, as the tokens of different clients may be the same.
+type progress struct {
+	mu      sync.Mutex // protects next and targets
+	next    uint64
+	targets map[string]progressTarget
+}
+
+// newProgress creates a new progress router.
+func newProgress() *progress {
+	return &progress{targets: make(map[string]progressTarget)}
+}
+
+// track returns the metadata of a request to send to the backend, with the
+// client's progress token replaced, and a function untracking the request
+// once handled.
+func (p *progress) track(ctx context.Context, meta *mcp.Meta) (*mcp.Meta, func()) {
+	if meta == nil || meta.ProgressToken == nil {
+		return meta, func() {}
+	}
+
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return meta, func() {}
+	}
+
+	p.mu.Lock()
+	p.next++
+	token := "posuer-" + strconv.FormatUint(p.next, 10)
+	p.targets[token] = progressTarget{sessionID: session.SessionID(), token: meta.ProgressToken}
+	p.mu.Unlock()
+
+	forwarded := *meta
+	forwarded.ProgressToken = token
+
+	return &forwarded, func() {
+		p.mu.Lock()
+		delete(p.targets, token)
+		p.mu.Unlock()
+	}
+}
+
+// target returns the client request a backend's progress token belongs to.
+func (p *progress) target(token mcp.ProgressToken) (progressTarget, bool) {
+	key, ok := token.(string)
+	if !ok {
+		return progressTarget{}, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	target, exists := p.targets[key]
+
+	return target, exists
+}
+
+// reportingProgress wraps a tool handler so that the progress the backend
+// reports on the call reaches the client that made it.
+func reportingProgress(tracker *progress, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		meta, done := tracker.track(ctx, request.Params.Meta)
+		defer done()
+
+		request.Params.Meta = meta
+
+		return handler(ctx, request)
+	}
+}
+
+// watchProgress forwards the progress notifications of a backend to the
+// clients whose calls they report on, with the clients' own progress tokens.
+func (i *Interposer) watchProgress(name string, mcpClient client.MCPClient) {
+	mcpClient.OnNotification(func(notification mcp.JSONRPCNotification) {
+		if notification.Method != methodNotificationProgress {
+			return
+		}
+
+		// Progress on calls that completed is dropped
+		target, exists := i.progress.target(notification.Params.AdditionalFields["progressToken"])
+		if !exists {
+			return
+		}
+
+		params := maps.Clone(notification.Params.AdditionalFields)
+		params["progressToken"] = target.token
+
+		err := i.server.SendNotificationToSpecificClient(target.sessionID, methodNotificationProgress, params)
+		if err != nil {
+			log.Printf("Warning: failed to forward progress from %s: %v", name, err)
+		}
+	})
+}
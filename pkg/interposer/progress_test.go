@@ -0,0 +1,124 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package interposer
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// progressClient is a mock client reporting progress on its tool calls.
+type progressClient struct {
+	*MockMCPClient
+
+	mu       sync.Mutex
+	handlers []func(notification mcp.JSONRPCNotification)
+	tokens   []mcp.ProgressToken
+}
+
+// OnNotification implements the OnNotification method of the MCPClient interface.
+func (c *progressClient) OnNotification(handler func(notification mcp.JSONRPCNotification)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.handlers = append(c.handlers, handler)
+}
+
+// notify sends a notification to the handlers.
+func (c *progressClient) notify(method string, params map[string]any) {
+	c.mu.Lock()
+	handlers := c.handlers
+	c.mu.Unlock()
+
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: method,
+			Params: mcp.NotificationParams{AdditionalFields: params},
+		},
+	}
+
+	for _, handler := range handlers {
+		handler(notification)
+	}
+}
+
+// CallTool implements the CallTool method of the MCPClient interface.
+func (c *progressClient) CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	token := request.Params.Meta.ProgressToken
+
+	c.mu.Lock()
+	c.tokens = append(c.tokens, token)
+	c.mu.Unlock()
+
+	c.notify(methodNotificationProgress, map[string]any{"progressToken": token, "progress": 1, "total": 2})
+
+	return c.MockMCPClient.CallTool(ctx, request)
+}
+
+func TestProgressForwarding(t *testing.T) {
+	t.Parallel()
+
+	backend := &progressClient{MockMCPClient: createMockClient()}
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(func(config.Server) (client.MCPClient, error) { return backend, nil }),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	require.NoError(t, interposerInstance.AddBackend(
+		context.Background(),
+		"backend",
+		config.Server{Name: "backend", Type: config.ServerTypeStdio},
+	))
+
+	mcpServer := interposerInstance.Server()
+
+	// Both clients use the same progress token
+	first, second := newFakeSession("first"), newFakeSession("second")
+
+	for _, session := range []*fakeSession{first, second} {
+		require.NoError(t, mcpServer.RegisterSession(context.Background(), session))
+
+		message := mcpServer.HandleMessage(mcpServer.WithContext(context.Background(), session), []byte(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "tools/call",
+			"params": {"name": "backend-test-tool", "_meta": {"progressToken": 42}}
+		}`))
+
+		_, ok := message.(mcp.JSONRPCResponse)
+		require.True(t, ok, "the call should succeed, got %#v", message)
+	}
+
+	// The backend is given a token of its own for each call
+	require.Len(t, backend.tokens, 2)
+	assert.NotEqual(t, backend.tokens[0], backend.tokens[1])
+	assert.NotEqual(t, float64(42), backend.tokens[0])
+
+	for _, session := range []*fakeSession{first, second} {
+		require.Len(t, session.notifications, 1)
+
+		notification := <-session.notifications
+		assert.Equal(t, methodNotificationProgress, notification.Method)
+		assert.Equal(t, map[string]any{"progressToken": float64(42), "progress": 1, "total": 2},
+			notification.Params.AdditionalFields)
+	}
+
+	// Progress on calls that completed is dropped
+	backend.notify(methodNotificationProgress, map[string]any{"progressToken": backend.tokens[0], "progress": 2})
+
+	assert.Empty(t, first.notifications)
+	assert.Empty(t, second.notifications)
+}
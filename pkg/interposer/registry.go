@@ -26,6 +26,18 @@ type CapabilityRegistry struct {
 	// Maps backend to the capabilities it provides
 	backendCaps map[string]map[CapabilityKey]bool
 
+	// Maps backend to its health
+	health map[string]Health
+
+	// Resolves capabilities of different backends with the same name or URI
+	policy CollisionPolicy
+
+	// Collisions between the registered backends
+	collisions []Collision
+
+	// Maps suffixed capabilities to the name their backend requested
+	requested map[CapabilityKey]string
+
 	mu sync.RWMutex
 }
 
@@ -34,6 +46,9 @@ func NewCapabilityRegistry() *CapabilityRegistry {
 	return &CapabilityRegistry{
 		capabilities: make(map[CapabilityKey]string),
 		backendCaps:  make(map[string]map[CapabilityKey]bool),
+		health:       make(map[string]Health),
+		policy:       CollisionPolicyError,
+		requested:    make(map[CapabilityKey]string),
 	}
 }
 
@@ -62,8 +77,11 @@ func (r *CapabilityRegistry) RemoveCapability(capType, capName string) (string,
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	key := CapabilityKey{Type: capType, Name: capName}
+	return r.removeCapability(CapabilityKey{Type: capType, Name: capName})
+}
 
+// removeCapability removes a specific capability, the lock must be held.
+func (r *CapabilityRegistry) removeCapability(key CapabilityKey) (string, bool) {
 	// Get the backend providing this capability
 	backend, exists := r.capabilities[key]
 	if !exists {
@@ -78,7 +96,10 @@ func (r *CapabilityRegistry) RemoveCapability(capType, capName string) (string,
 		delete(backendCaps, key)
 	}
 
-	log.Printf("Removed capability %s of type %s from backend %s", capName, capType, backend)
+	r.forgetCollisions(backend, key)
+	delete(r.requested, key)
+
+	log.Printf("Removed capability %s of type %s from backend %s", key.Name, key.Type, backend)
 
 	return backend, true
 }
@@ -91,6 +112,18 @@ func (r *CapabilityRegistry) RemoveBackendCapabilities(backend string) map[strin
 
 	removedByType := make(map[string][]string)
 
+	// Forget the collisions the backend was involved in, including those
+	// that kept its capabilities from being registered
+	collisions := r.collisions[:0]
+
+	for _, collision := range r.collisions {
+		if collision.Owner != backend && collision.Backend != backend {
+			collisions = append(collisions, collision)
+		}
+	}
+
+	r.collisions = collisions
+
 	// Get capabilities for this backend
 	backendCaps, exists := r.backendCaps[backend]
 	if !exists {
@@ -108,6 +141,7 @@ func (r *CapabilityRegistry) RemoveBackendCapabilities(backend string) map[strin
 
 		// Remove from capabilities map
 		delete(r.capabilities, key)
+		delete(r.requested, key)
 	}
 
 	// Remove backend from backendCaps map
@@ -217,3 +251,45 @@ func (r *CapabilityRegistry) GetCapabilityTypes() map[string]bool {
 
 	return types
 }
+
+// SetBackendHealth records the health of a backend.
+func (r *CapabilityRegistry) SetBackendHealth(backend string, health Health) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.health[backend] = health
+}
+
+// RemoveBackendHealth forgets the health of a backend.
+func (r *CapabilityRegistry) RemoveBackendHealth(backend string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.health, backend)
+}
+
+// GetBackendHealth returns the health of a backend, unknown if it is not connected.
+func (r *CapabilityRegistry) GetBackendHealth(backend string) Health {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	health, exists := r.health[backend]
+	if !exists {
+		return HealthUnknown
+	}
+
+	return health
+}
+
+// GetAllBackendHealth returns the health of all connected backends.
+func (r *CapabilityRegistry) GetAllBackendHealth() map[string]Health {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]Health, len(r.health))
+	for backend, health := range r.health {
+		result[backend] = health
+	}
+
+	return result
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/jkoelker/posuer/pkg/interposer"
 )
@@ -142,4 +143,102 @@ func TestCapabilityRegistry(t *testing.T) {
 		assert.Len(t, capabilities["tool"], 1)
 		assert.Contains(t, capabilities["tool"], "tool3")
 	})
+	t.Run("backend health", func(t *testing.T) {
+		t.Parallel()
+
+		registry := interposer.NewCapabilityRegistry()
+		assert.Equal(t, interposer.HealthUnknown, registry.GetBackendHealth("backend1"))
+
+		registry.SetBackendHealth("backend1", interposer.HealthHealthy)
+		registry.SetBackendHealth("backend2", interposer.HealthUnhealthy)
+
+		assert.Equal(t, interposer.HealthHealthy, registry.GetBackendHealth("backend1"))
+		assert.Equal(t, map[string]interposer.Health{
+			"backend1": interposer.HealthHealthy,
+			"backend2": interposer.HealthUnhealthy,
+		}, registry.GetAllBackendHealth())
+
+		registry.RemoveBackendHealth("backend2")
+		assert.Equal(t, interposer.HealthUnknown, registry.GetBackendHealth("backend2"))
+	})
+
+	t.Run("collision policies", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			policy     interposer.CollisionPolicy
+			registered string
+			displaced  []string
+			err        error
+		}{
+			{policy: interposer.CollisionPolicyError, err: interposer.ErrCapabilityCollision},
+			{policy: interposer.CollisionPolicyFirstWins},
+			{policy: interposer.CollisionPolicyLastWins, registered: "tool1", displaced: []string{"tool1"}},
+			{policy: interposer.CollisionPolicyAutoSuffix, registered: "tool1-2"},
+		}
+
+		for _, test := range tests {
+			t.Run(string(test.policy), func(t *testing.T) {
+				t.Parallel()
+
+				registry := interposer.NewCapabilityRegistry()
+				require.NoError(t, registry.SetCollisionPolicy(test.policy))
+
+				registry.AddCapability("backend1", "tool", "tool1")
+
+				registered, displaced, err := registry.Claim("backend2", "tool", "tool1", "")
+				require.ErrorIs(t, err, test.err)
+				assert.Equal(t, test.registered, registered)
+				assert.Equal(t, test.displaced, displaced)
+
+				assert.Equal(t, []interposer.Collision{{
+					Type:       "tool",
+					Name:       "tool1",
+					Owner:      "backend1",
+					Backend:    "backend2",
+					Policy:     test.policy,
+					Registered: test.registered,
+				}}, registry.GetCollisionsForBackend("backend2"))
+
+				if registered != "" {
+					registry.AddCapability("backend2", "tool", registered)
+					assert.Equal(t, "tool1", registry.GetRequestedName("tool", registered))
+				}
+
+				// Claiming again resolves to the same name
+				again, _, _ := registry.Claim("backend2", "tool", "tool1", "")
+				assert.Equal(t, registered, again)
+				assert.Len(t, registry.GetCollisions(), 1)
+
+				registry.RemoveBackendCapabilities("backend2")
+				assert.Empty(t, registry.GetCollisions())
+			})
+		}
+	})
+
+	t.Run("shared uri", func(t *testing.T) {
+		t.Parallel()
+
+		registry := interposer.NewCapabilityRegistry()
+		require.NoError(t, registry.SetCollisionPolicy(interposer.CollisionPolicyAutoSuffix))
+
+		registry.AddCapability("backend1", "resource", "resource1")
+
+		// Resources sharing a URI can not be suffixed
+		_, _, err := registry.Claim("backend2", "resource", "resource2", "resource1")
+		require.ErrorIs(t, err, interposer.ErrCapabilityCollision)
+
+		// Nor do they collide with the backend's own resources
+		registered, _, err := registry.Claim("backend1", "resource", "resource2", "resource1")
+		require.NoError(t, err)
+		assert.Equal(t, "resource2", registered)
+	})
+
+	t.Run("unknown collision policy", func(t *testing.T) {
+		t.Parallel()
+
+		registry := interposer.NewCapabilityRegistry()
+		require.ErrorIs(t, registry.SetCollisionPolicy("random"), interposer.ErrUnknownCollisionPolicy)
+		assert.Equal(t, interposer.CollisionPolicyError, registry.GetCollisionPolicy())
+	})
 }
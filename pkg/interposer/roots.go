@@ -0,0 +1,238 @@
+package interposer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ErrRootsUnsupported is returned for a roots request when no client supports roots.
+var ErrRootsUnsupported = errors.New("no client supports roots")
+
+// methodNotificationInitialized is sent by a client once initialized.
+const methodNotificationInitialized = "notifications/initialized"
+
+// rootsNotifier is implemented by clients that notify their server of root changes.
+type rootsNotifier interface {
+	RootListChanges(ctx context.Context) error
+}
+
+// roots tracks the client sessions that declared roots, so that the roots
+// requests of backends reach a client able to answer them.
+type roots struct {
+	mu       sync.Mutex // protects sessions
+	sessions []server.ClientSession
+}
+
+// newRoots creates a new roots tracker.
+func newRoots() *roots {
+	return &roots{}
+}
+
+// hooks adds the server hooks tracking the sessions declaring roots.
+func (r *roots) hooks(hooks *server.Hooks) {
+	hooks.AddAfterInitialize(func(ctx context.Context, _ any, _ *mcp.InitializeRequest, _ *mcp.InitializeResult) {
+		if !supportsRoots(ctx) {
+			return
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.sessions = append(r.sessions, server.ClientSessionFromContext(ctx))
+	})
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.sessions = slices.DeleteFunc(r.sessions, func(tracked server.ClientSession) bool {
+			return tracked.SessionID() == session.SessionID()
+		})
+	})
+}
+
+// only returns the session declaring roots, as long as it is the only one.
+func (r *roots) only() (server.ClientSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch len(r.sessions) {
+	case 0:
+		return nil, ErrRootsUnsupported
+	case 1:
+		return r.sessions[0], nil
+	default:
+		return nil, ErrAmbiguousCaller
+	}
+}
+
+// supportsRoots returns true if the client of the context declared roots.
+func supportsRoots(ctx context.Context) bool {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+
+	return ok && session.GetClientCapabilities().Roots != nil
+}
+
+// rooter relays the roots requests of a backend to a client, translating
+// the roots to the paths they are mounted at for containerized backends.
+type rooter struct {
+	interposer *Interposer
+	backend    string
+	volumes    map[string]string
+}
+
+// ListRoots implements the client.RootsHandler interface.
+func (r *rooter) ListRoots(
+	ctx context.Context,
+	request mcp.ListRootsRequest,
+) (*mcp.ListRootsResult, error) {
+	caller, err := r.caller(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: roots request from %s", err, r.backend)
+	}
+
+	result, err := r.interposer.server.RequestRoots(caller, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to relay roots request from %s: %w", r.backend, err)
+	}
+
+	if r.volumes != nil {
+		result.Roots = translateRoots(result.Roots, r.volumes)
+	}
+
+	return result, nil
+}
+
+// caller returns the context of the client whose roots the backend is given:
+// the one calling its tools, or outside of calls the only client declaring
+// roots. Requests that cannot be attributed to one client are not answered.
+func (r *rooter) caller(ctx context.Context) (context.Context, error) {
+	caller, err := r.interposer.callers.caller(r.backend)
+	if errors.Is(err, ErrNoCaller) {
+		session, err := r.interposer.roots.only()
+		if err != nil {
+			return nil, err
+		}
+
+		return r.interposer.server.WithContext(ctx, session), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !supportsRoots(caller) {
+		return nil, ErrRootsUnsupported
+	}
+
+	return caller, nil
+}
+
+// translateRoots returns the roots at the paths they are mounted at in a
+// container, given its volumes mapping host paths to container paths.
+// Roots that are not mounted are dropped, as the container cannot reach them.
+func translateRoots(roots []mcp.Root, volumes map[string]string) []mcp.Root {
+	translated := make([]mcp.Root, 0, len(roots))
+
+	for _, root := range roots {
+		uri, err := url.Parse(root.URI)
+		if err != nil || uri.Scheme != "file" {
+			// Only file roots name host paths
+			translated = append(translated, root)
+
+			continue
+		}
+
+		path, mounted := containerPath(filepath.Clean(uri.Path), volumes)
+		if !mounted {
+			log.Printf("Warning: root %s is not mounted in the container, dropping it", root.URI)
+
+			continue
+		}
+
+		root.URI = (&url.URL{Scheme: "file", Path: path}).String()
+		translated = append(translated, root)
+	}
+
+	return translated
+}
+
+// containerPath returns the path a host path is mounted at in a container,
+// using the most specific volume that contains it.
+func containerPath(path string, volumes map[string]string) (string, bool) {
+	var host, container string
+
+	for source, target := range volumes {
+		source = filepath.Clean(source)
+		if !filepath.IsAbs(source) || len(source) <= len(host) {
+			continue
+		}
+
+		if path == source || strings.HasPrefix(path, strings.TrimSuffix(source, "/")+"/") {
+			host, container = source, target
+		}
+	}
+
+	if host == "" {
+		return "", false
+	}
+
+	// Volume targets may carry options, like /data:ro
+	container, _, _ = strings.Cut(container, ":")
+
+	rel, err := filepath.Rel(host, path)
+	if err != nil {
+		return "", false
+	}
+
+	return filepath.Join(container, rel), true
+}
+
+// forwardRoots notifies the backends when the roots of the clients change.
+// Backends connect before clients do, so they are also notified once a client
+// declaring roots is initialized, to ask for them again.
+func (i *Interposer) forwardRoots() {
+	i.server.AddNotificationHandler(
+		methodNotificationInitialized,
+		func(ctx context.Context, _ mcp.JSONRPCNotification) {
+			if supportsRoots(ctx) {
+				i.rootsChanged(ctx)
+			}
+		},
+	)
+	i.server.AddNotificationHandler(
+		mcp.MethodNotificationRootsListChanged,
+		func(ctx context.Context, _ mcp.JSONRPCNotification) {
+			i.rootsChanged(ctx)
+		},
+	)
+}
+
+// rootsChanged notifies the backends that the roots of the clients changed.
+// Lazy backends that are not running ask for the roots once started.
+func (i *Interposer) rootsChanged(ctx context.Context) {
+	i.mu.RLock()
+	clients := make(map[string]rootsNotifier, len(i.clients))
+
+	for name, mcpClient := range i.clients {
+		if notifier, ok := mcpClient.(rootsNotifier); ok {
+			clients[name] = notifier
+		}
+	}
+	i.mu.RUnlock()
+
+	for name, notifier := range clients {
+		if err := notifier.RootListChanges(ctx); err != nil {
+			log.Printf("Warning: failed to notify %s of root changes: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package interposer
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// rootsSession is a frontend client session answering roots requests.
+type rootsSession struct {
+	*samplingSession
+
+	roots []mcp.Root
+}
+
+// ListRoots implements the server.SessionWithRoots interface.
+func (s *rootsSession) ListRoots(context.Context, mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
+	return &mcp.ListRootsResult{Roots: s.roots}, nil
+}
+
+// rootsClient is a mock client counting the root changes it is notified of.
+type rootsClient struct {
+	*MockMCPClient
+
+	changes atomic.Int32
+}
+
+// RootListChanges implements the rootsNotifier interface.
+func (c *rootsClient) RootListChanges(context.Context) error {
+	c.changes.Add(1)
+
+	return nil
+}
+
+func TestTranslateRoots(t *testing.T) {
+	t.Parallel()
+
+	volumes := map[string]string{
+		"/home/user/project":      config.DefaultContainerWorkDir,
+		"/home/user/project/data": "/data:ro",
+		"relative":                "/relative",
+	}
+
+	roots := translateRoots([]mcp.Root{
+		{URI: "file:///home/user/project", Name: "project"},
+		{URI: "file:///home/user/project/src/"},
+		{URI: "file:///home/user/project/data/set"},
+		{URI: "file:///home/user/projects"},
+		{URI: "file:///etc"},
+		{URI: "https://example.com/repo"},
+	}, volumes)
+
+	assert.Equal(t, []mcp.Root{
+		{URI: "file:///code", Name: "project"},
+		{URI: "file:///code/src"},
+		{URI: "file:///data/set"},
+		{URI: "https://example.com/repo"},
+	}, roots)
+}
+
+func TestRootsPassthrough(t *testing.T) {
+	t.Parallel()
+
+	backend := &rootsClient{MockMCPClient: createMockClient()}
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(func(config.Server) (client.MCPClient, error) { return backend, nil }),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	require.NoError(t, interposerInstance.AddBackend(
+		context.Background(),
+		"backend",
+		config.Server{Name: "backend", Type: config.ServerTypeStdio},
+	))
+
+	plain := &rooter{interposer: interposerInstance, backend: "backend"}
+	containerized := &rooter{
+		interposer: interposerInstance,
+		backend:    "backend",
+		volumes:    map[string]string{"/home/user/project": config.DefaultContainerWorkDir},
+	}
+
+	// Without a client declaring roots there is nobody to ask
+	_, err = plain.ListRoots(context.Background(), mcp.ListRootsRequest{})
+	require.ErrorIs(t, err, ErrRootsUnsupported)
+
+	mcpServer := interposerInstance.Server()
+	session := &rootsSession{
+		samplingSession: &samplingSession{fakeSession: newFakeSession("first")},
+		roots:           []mcp.Root{{URI: "file:///home/user/project/src"}, {URI: "file:///tmp"}},
+	}
+	ctx := mcpServer.WithContext(context.Background(), session)
+
+	require.NoError(t, mcpServer.RegisterSession(context.Background(), session))
+
+	message := mcpServer.HandleMessage(ctx, []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "initialize",
+		"params": {
+			"protocolVersion": "2025-03-26",
+			"capabilities": {"roots": {"listChanged": true}},
+			"clientInfo": {"name": "test", "version": "1.0.0"}
+		}
+	}`))
+
+	_, ok := message.(mcp.JSONRPCResponse)
+	require.True(t, ok, "initialize should succeed, got %#v", message)
+
+	// Backends are told to ask again once the client is initialized
+	mcpServer.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "method": "notifications/initialized"}`))
+	assert.Equal(t, int32(1), backend.changes.Load())
+
+	result, err := plain.ListRoots(context.Background(), mcp.ListRootsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, session.roots, result.Roots)
+
+	// Containerized backends see the roots where they are mounted
+	result, err = containerized.ListRoots(context.Background(), mcp.ListRootsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []mcp.Root{{URI: "file:///code/src"}}, result.Roots)
+
+	// Changes to the client's roots are forwarded
+	mcpServer.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "method": "notifications/roots/list_changed"}`))
+	assert.Equal(t, int32(2), backend.changes.Load())
+
+	// With another client declaring roots, requests outside of calls cannot
+	// be attributed to either of them
+	other := &rootsSession{
+		samplingSession: &samplingSession{fakeSession: newFakeSession("second")},
+		roots:           []mcp.Root{{URI: "file:///home/other"}},
+	}
+
+	require.NoError(t, mcpServer.RegisterSession(context.Background(), other))
+	mcpServer.HandleMessage(mcpServer.WithContext(context.Background(), other), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "initialize",
+		"params": {
+			"protocolVersion": "2025-03-26",
+			"capabilities": {"roots": {}},
+			"clientInfo": {"name": "test", "version": "1.0.0"}
+		}
+	}`))
+
+	_, err = plain.ListRoots(context.Background(), mcp.ListRootsRequest{})
+	require.ErrorIs(t, err, ErrAmbiguousCaller)
+
+	// During a call the roots of the calling client are given
+	done := interposerInstance.callers.track(mcpServer.WithContext(context.Background(), other), "backend")
+
+	result, err = plain.ListRoots(context.Background(), mcp.ListRootsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, other.roots, result.Roots)
+
+	// Unless another client is calling the backend's tools too
+	doneFirst := interposerInstance.callers.track(ctx, "backend")
+
+	_, err = plain.ListRoots(context.Background(), mcp.ListRootsRequest{})
+	require.ErrorIs(t, err, ErrAmbiguousCaller)
+
+	doneFirst()
+	done()
+
+	// Roots are no longer asked of disconnected clients
+	mcpServer.UnregisterSession(context.Background(), other.SessionID())
+	mcpServer.UnregisterSession(context.Background(), session.SessionID())
+
+	_, err = plain.ListRoots(context.Background(), mcp.ListRootsRequest{})
+	require.ErrorIs(t, err, ErrRootsUnsupported)
+}
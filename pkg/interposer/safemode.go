@@ -0,0 +1,102 @@
+package interposer
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// SafeModeError is the error reported in the structured content of a tool
+// call rejected in safe mode.
+const SafeModeError = "safe_mode"
+
+// SafeMode returns true if the interposer is in safe mode.
+func (i *Interposer) SafeMode() bool {
+	return i.safeMode.Load()
+}
+
+// SetSafeMode puts the interposer in or out of safe mode. In safe mode only
+// tools annotated as read-only or matching a server's safe tools are exposed,
+// and calls to any other tool are rejected. The tools of every backend are
+// updated to match and clients are notified.
+func (i *Interposer) SetSafeMode(enabled bool) {
+	i.lifecycle.Lock()
+	defer i.lifecycle.Unlock()
+
+	if i.safeMode.Swap(enabled) == enabled {
+		return
+	}
+
+	log.Printf("Safe mode is now %t", enabled)
+
+	i.mu.RLock()
+
+	clients := make(map[string]client.MCPClient, len(i.clients))
+	for name, mcpClient := range i.clients {
+		clients[name] = mcpClient
+	}
+
+	configs := make(map[string]config.Server, len(i.configs))
+	for name, cfg := range i.configs {
+		configs[name] = cfg
+	}
+
+	i.mu.RUnlock()
+
+	toolsChanged := false
+
+	for name, mcpClient := range clients {
+		changed, err := i.syncTools(name, mcpClient, configs[name])
+		if err != nil {
+			log.Printf("Warning: failed to apply safe mode to %s: %v", name, err)
+		}
+
+		toolsChanged = toolsChanged || changed
+	}
+
+	i.sendNotifications(toolsChanged, false, false, false)
+}
+
+// toolEnabled returns true if a backend's tool is enabled by its
+// configuration and, in safe mode, is safe.
+func (i *Interposer) toolEnabled(cfg config.Server, name string, annotations map[string]bool) bool {
+	if !cfg.ToolEnabled(name, annotations) {
+		return false
+	}
+
+	return !i.SafeMode() || cfg.SafeTool(name, annotations)
+}
+
+// toolAllowed returns a function reporting whether a backend's tool may be
+// called, checking the current mode and configuration at call time.
+func (i *Interposer) toolAllowed(name string, tool mcp.Tool) func() bool {
+	return func() bool {
+		if !i.SafeMode() {
+			return true
+		}
+
+		i.mu.RLock()
+		cfg := i.configs[name]
+		i.mu.RUnlock()
+
+		return cfg.SafeTool(tool.Name, toolAnnotations(overrideTool(cfg, tool)))
+	}
+}
+
+// safeModeError returns the result of a tool call rejected in safe mode.
+func safeModeError(name string) *mcp.CallToolResult {
+	message := fmt.Sprintf("tool %s is not read-only and cannot be called in safe mode", name)
+
+	result := mcp.NewToolResultStructured(map[string]any{
+		"error":   SafeModeError,
+		"tool":    name,
+		"message": message,
+	}, message)
+	result.IsError = true
+
+	return result
+}
@@ -0,0 +1,100 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package interposer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// safeModeClientFactory returns a factory for clients with read-only and mutating tools.
+func safeModeClientFactory() func(config.Server) (client.MCPClient, error) {
+	return func(_ config.Server) (client.MCPClient, error) {
+		mockClient := createMockClient()
+		mockClient.tools = []mcp.Tool{
+			mcp.NewTool("read_file", mcp.WithReadOnlyHintAnnotation(true)),
+			mcp.NewTool("write_file", mcp.WithDestructiveHintAnnotation(false)),
+			mcp.NewTool("delete_file"),
+		}
+
+		return mockClient, nil
+	}
+}
+
+// toolNames returns the names of the tools exposed by the interposer.
+func toolNames(interposerInstance *Interposer) []string {
+	var names []string
+
+	for name := range interposerInstance.Server().ListTools() {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func TestSafeMode(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(safeModeClientFactory()),
+		WithSafeMode(true),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	session := newFakeSession("session")
+	require.NoError(t, interposerInstance.Server().RegisterSession(context.Background(), session))
+
+	require.NoError(t, interposerInstance.Reconfigure(context.Background(), []config.Server{
+		{Name: "files", Type: config.ServerTypeStdio},
+		{Name: "allowed", Type: config.ServerTypeStdio, SafeTools: []string{"write_*"}},
+	}))
+
+	session.methods()
+
+	// Only read-only and allow-listed tools are exposed
+	assert.ElementsMatch(t, []string{
+		"files-read_file", "allowed-read_file", "allowed-write_file",
+	}, toolNames(interposerInstance))
+
+	// Leaving safe mode exposes everything
+	interposerInstance.SetSafeMode(false)
+
+	assert.Len(t, toolNames(interposerInstance), 6)
+	assert.Equal(t, []string{"notifications/tools/list_changed"}, session.methods())
+
+	// Setting the same mode again changes nothing
+	interposerInstance.SetSafeMode(false)
+
+	assert.Empty(t, session.methods())
+
+	// Calls to a tool listed before entering safe mode are rejected
+	deleteFile := interposerInstance.Server().GetTool("files-delete_file")
+	require.NotNil(t, deleteFile)
+
+	interposerInstance.SetSafeMode(true)
+
+	assert.Nil(t, interposerInstance.Server().GetTool("files-delete_file"))
+	assert.Equal(t, []string{"notifications/tools/list_changed"}, session.methods())
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "files-delete_file"
+
+	result, err := deleteFile.Handler(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, map[string]any{
+		"error":   SafeModeError,
+		"tool":    "files-delete_file",
+		"message": "tool files-delete_file is not read-only and cannot be called in safe mode",
+	}, result.StructuredContent)
+}
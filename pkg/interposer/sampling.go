@@ -0,0 +1,186 @@
+package interposer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jkoelker/posuer/pkg/config"
+	"github.com/jkoelker/posuer/pkg/isolate"
+)
+
+var (
+	// ErrNoCaller is returned for a request a backend makes while no client
+	// is calling one of its tools.
+	ErrNoCaller = errors.New("no tool call in progress")
+
+	// ErrAmbiguousCaller is returned for a request a backend makes while
+	// more than one client is calling its tools, as the request cannot be
+	// attributed to one of them.
+	ErrAmbiguousCaller = errors.New("tool calls in progress from more than one client")
+
+	// ErrSamplingUnsupported is returned for a sampling request when the
+	// calling client does not support sampling.
+	ErrSamplingUnsupported = errors.New("client does not support sampling")
+)
+
+// callers tracks the tool calls in progress on each backend, so that the
+// requests a backend makes while handling a call reach the client that made it.
+type callers struct {
+	mu     sync.Mutex // protects active
+	next   uint64
+	active map[string]map[uint64]context.Context
+}
+
+// newCallers creates a new tool call tracker.
+func newCallers() *callers {
+	return &callers{active: make(map[string]map[uint64]context.Context)}
+}
+
+// track records a call to a backend, returning a function untracking it once handled.
+func (c *callers) track(ctx context.Context, backend string) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.next++
+	call := c.next
+
+	if c.active[backend] == nil {
+		c.active[backend] = make(map[uint64]context.Context)
+	}
+
+	c.active[backend][call] = ctx
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		delete(c.active[backend], call)
+
+		if len(c.active[backend]) == 0 {
+			delete(c.active, backend)
+		}
+	}
+}
+
+// caller returns the context of a call in progress on a backend. Backends do
+// not say which call a request belongs to, so a request is only attributed
+// when every call in progress on the backend comes from the same session.
+func (c *callers) caller(backend string) (context.Context, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		latest  context.Context
+		call    uint64
+		session string
+	)
+
+	for id, ctx := range c.active[backend] {
+		if latest != nil && sessionID(ctx) != session {
+			return nil, ErrAmbiguousCaller
+		}
+
+		session = sessionID(ctx)
+
+		if id > call {
+			latest, call = ctx, id
+		}
+	}
+
+	if latest == nil {
+		return nil, ErrNoCaller
+	}
+
+	return latest, nil
+}
+
+// sessionID returns the ID of the client session of a context, if any.
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+
+	return ""
+}
+
+// fromCaller wraps a tool handler so that requests the backend makes while
+// handling the call are sent to the calling client.
+func fromCaller(tracker *callers, backend string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		done := tracker.track(ctx, backend)
+		defer done()
+
+		return handler(ctx, request)
+	}
+}
+
+// sampler relays the sampling requests of a backend to the client calling
+// one of its tools.
+type sampler struct {
+	interposer *Interposer
+	backend    string
+}
+
+// CreateMessage implements the client.SamplingHandler interface.
+func (s *sampler) CreateMessage(
+	_ context.Context,
+	request mcp.CreateMessageRequest,
+) (*mcp.CreateMessageResult, error) {
+	caller, err := s.interposer.callers.caller(s.backend)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sampling request from %s", err, s.backend)
+	}
+
+	if !supportsSampling(caller) {
+		return nil, fmt.Errorf("%w: sampling request from %s", ErrSamplingUnsupported, s.backend)
+	}
+
+	result, err := s.interposer.server.RequestSampling(caller, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to relay sampling request from %s: %w", s.backend, err)
+	}
+
+	return result, nil
+}
+
+// supportsSampling returns true if the client of the context declared sampling.
+func supportsSampling(ctx context.Context) bool {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+
+	return ok && session.GetClientCapabilities().Sampling != nil
+}
+
+// newClient creates the client of a backend, relaying the requests it makes
+// to the clients where allowed.
+func (i *Interposer) newClient(cfg config.Server) (client.MCPClient, error) {
+	return isolate.Client(cfg, i.clientOptions(cfg)...) //nolint:wrapcheck // Transparent wrapper
+}
+
+// clientOptions returns the options of the client of a backend.
+func (i *Interposer) clientOptions(cfg config.Server) []client.ClientOption {
+	// Roots are relayed to every backend, translated to the paths they are
+	// mounted at for containerized ones
+	volumes, err := isolate.Volumes(cfg)
+	if err != nil {
+		log.Printf("Warning: failed to resolve the volumes of %s, roots are not translated: %v", cfg.Name, err)
+	}
+
+	options := []client.ClientOption{
+		client.WithRootsHandler(&rooter{interposer: i, backend: cfg.Name, volumes: volumes}),
+	}
+
+	// Declares sampling to the backend, which is relayed if the calling
+	// client supports it, as backends connect before clients do
+	if cfg.AllowSampling {
+		options = append(options, client.WithSamplingHandler(&sampler{interposer: i, backend: cfg.Name}))
+	}
+
+	return options
+}
@@ -0,0 +1,184 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package interposer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// samplingSession is a frontend client session answering sampling requests.
+type samplingSession struct {
+	*fakeSession
+
+	capabilities mcp.ClientCapabilities
+}
+
+// GetClientInfo implements the server.SessionWithClientInfo interface.
+func (s *samplingSession) GetClientInfo() mcp.Implementation {
+	return mcp.Implementation{Name: "test", Version: "1.0.0"}
+}
+
+// SetClientInfo implements the server.SessionWithClientInfo interface.
+func (s *samplingSession) SetClientInfo(mcp.Implementation) {}
+
+// GetClientCapabilities implements the server.SessionWithClientInfo interface.
+func (s *samplingSession) GetClientCapabilities() mcp.ClientCapabilities {
+	return s.capabilities
+}
+
+// SetClientCapabilities implements the server.SessionWithClientInfo interface.
+func (s *samplingSession) SetClientCapabilities(capabilities mcp.ClientCapabilities) {
+	s.capabilities = capabilities
+}
+
+// RequestSampling implements the server.SessionWithSampling interface.
+func (s *samplingSession) RequestSampling(
+	_ context.Context,
+	request mcp.CreateMessageRequest,
+) (*mcp.CreateMessageResult, error) {
+	prompt, _ := request.Messages[0].Content.(mcp.TextContent)
+
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{
+			Role:    mcp.RoleAssistant,
+			Content: mcp.NewTextContent("sampled " + prompt.Text + " for " + s.id),
+		},
+		Model: "test",
+	}, nil
+}
+
+// samplingClient is a mock client whose tool calls ask the client's LLM for
+// a completion, as a backend does while handling a call.
+type samplingClient struct {
+	*MockMCPClient
+
+	sampler *sampler
+}
+
+// CallTool implements the CallTool method of the MCPClient interface.
+func (c *samplingClient) CallTool(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	request := mcp.CreateMessageRequest{}
+	request.Messages = []mcp.SamplingMessage{{Role: mcp.RoleUser, Content: mcp.NewTextContent("hello")}}
+
+	result, err := c.sampler.CreateMessage(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	text, _ := result.Content.(mcp.TextContent)
+
+	return mcp.NewToolResultText(text.Text), nil
+}
+
+func TestSamplingPassthrough(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer("TestInterposer", "1.0.0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	backend := &sampler{interposer: interposerInstance, backend: "backend"}
+	mcpClient := &samplingClient{MockMCPClient: createMockClient(), sampler: backend}
+	tool := mcp.NewTool("ask")
+	cfg := config.Server{Name: "backend", AllowSampling: true}
+
+	require.NoError(t, interposerInstance.RegisterTool(
+		"backend",
+		tool,
+		handleTool(cfg, tool, mcpClient, func() bool { return true }),
+	))
+
+	assert.Len(t, interposerInstance.clientOptions(cfg), 2)
+	assert.Len(t, interposerInstance.clientOptions(config.Server{Name: "backend"}), 1)
+
+	mcpServer := interposerInstance.Server()
+
+	call := func(session *samplingSession) string {
+		require.NoError(t, mcpServer.RegisterSession(context.Background(), session))
+
+		message := mcpServer.HandleMessage(mcpServer.WithContext(context.Background(), session), []byte(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "tools/call",
+			"params": {"name": "ask"}
+		}`))
+
+		response, ok := message.(mcp.JSONRPCResponse)
+		require.True(t, ok, "the call should succeed, got %#v", message)
+
+		result, ok := response.Result.(*mcp.CallToolResult)
+		require.True(t, ok, "result should be a CallToolResult, got %T", response.Result)
+
+		text, _ := result.Content[0].(mcp.TextContent)
+
+		return text.Text
+	}
+
+	// The request reaches the client that made the call
+	assert.Equal(t, "sampled hello for first", call(&samplingSession{
+		fakeSession:  newFakeSession("first"),
+		capabilities: mcp.ClientCapabilities{Sampling: &struct{}{}},
+	}))
+
+	// Clients that do not support sampling are not asked
+	assert.Contains(t, call(&samplingSession{fakeSession: newFakeSession("second")}), ErrSamplingUnsupported.Error())
+
+	// Requests made outside of a call have nowhere to go
+	_, err = backend.CreateMessage(context.Background(), mcp.CreateMessageRequest{})
+	require.ErrorIs(t, err, ErrNoCaller)
+}
+
+func TestCallersAmbiguous(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer("TestInterposer", "1.0.0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	mcpServer := interposerInstance.Server()
+	tracker := newCallers()
+
+	first := mcpServer.WithContext(context.Background(), newFakeSession("first"))
+	second := mcpServer.WithContext(context.Background(), newFakeSession("second"))
+
+	_, err = tracker.caller("backend")
+	require.ErrorIs(t, err, ErrNoCaller)
+
+	// Concurrent calls from the same client are attributed to it
+	doneFirst := tracker.track(first, "backend")
+	doneAgain := tracker.track(first, "backend")
+
+	caller, err := tracker.caller("backend")
+	require.NoError(t, err)
+	assert.Equal(t, "first", sessionID(caller))
+
+	// Calls from another client make requests ambiguous, they are not guessed
+	doneSecond := tracker.track(second, "backend")
+
+	_, err = tracker.caller("backend")
+	require.ErrorIs(t, err, ErrAmbiguousCaller)
+
+	// Calls on other backends do not matter
+	_, err = tracker.caller("other")
+	require.ErrorIs(t, err, ErrNoCaller)
+
+	doneFirst()
+	doneAgain()
+
+	caller, err = tracker.caller("backend")
+	require.NoError(t, err)
+	assert.Equal(t, "second", sessionID(caller))
+
+	doneSecond()
+
+	_, err = tracker.caller("backend")
+	require.ErrorIs(t, err, ErrNoCaller)
+}
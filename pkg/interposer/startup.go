@@ -0,0 +1,91 @@
+package interposer
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+const (
+	// DefaultInitTimeout is the default time a backend may take to connect and initialize.
+	DefaultInitTimeout = 30 * time.Second
+
+	// DefaultStartupTimeout is the default time all backends may take to start.
+	DefaultStartupTimeout = time.Minute
+)
+
+// StartBackends connects to the backends concurrently, giving each of them
+// initTimeout to initialize unless it configures its own initialize timeout.
+// Backends still starting once ctx is done fail. Backends are registered as
+// they finish, so clients already connected are notified of their
+// capabilities. Returns the errors of the backends that failed to start,
+// keyed by name.
+func (i *Interposer) StartBackends(
+	ctx context.Context,
+	serverConfigs []config.Server,
+	initTimeout time.Duration,
+) map[string]error {
+	// Configuration changes wait for the backends to start
+	i.lifecycle.Lock()
+	defer i.lifecycle.Unlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = make(map[string]error)
+	)
+
+	for _, serverConfig := range serverConfigs {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			initCtx := ctx
+
+			// The server's own initialize timeout takes precedence
+			timeout := initTimeout
+			if serverConfig.Timeouts.Initialize > 0 {
+				timeout = serverConfig.Timeouts.Initialize.Duration()
+			}
+
+			if timeout > 0 {
+				var cancel context.CancelFunc
+
+				initCtx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			log.Printf("Connecting to backend server: %s", serverConfig.Name)
+
+			if err := i.AddBackend(initCtx, serverConfig.Name, serverConfig); err != nil {
+				mu.Lock()
+				errs[serverConfig.Name] = err
+				mu.Unlock()
+
+				return
+			}
+
+			log.Printf("Connected to backend server: %s", serverConfig.Name)
+
+			// Clients already being served learn about the new capabilities
+			i.notifyBackendCapabilities(serverConfig.Name)
+		}()
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+// closeClient closes a client, logging any error.
+func closeClient(name string, mcpClient client.MCPClient) {
+	if err := mcpClient.Close(); err != nil {
+		log.Printf("Error closing client %s: %v", name, err)
+	}
+}
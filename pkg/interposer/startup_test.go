@@ -0,0 +1,124 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package interposer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// hangingMCPClient is a mock client whose backend never answers initialize.
+type hangingMCPClient struct {
+	*MockMCPClient
+}
+
+// Initialize implements the Initialize method of the MCPClient interface.
+func (m *hangingMCPClient) Initialize(
+	ctx context.Context,
+	_ mcp.InitializeRequest,
+) (*mcp.InitializeResult, error) {
+	<-ctx.Done()
+
+	return nil, ctx.Err()
+}
+
+// unreachableMCPClient is a mock client whose transport never connects until closed.
+type unreachableMCPClient struct {
+	*MockMCPClient
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Start implements the starter interface.
+func (m *unreachableMCPClient) Start(_ context.Context) error {
+	<-m.closed
+
+	return context.Canceled
+}
+
+// Close implements the Close method of the MCPClient interface.
+func (m *unreachableMCPClient) Close() error {
+	m.closeOnce.Do(func() { close(m.closed) })
+
+	return nil
+}
+
+func TestStartBackendsConcurrently(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(func(cfg config.Server) (client.MCPClient, error) {
+			switch cfg.Name {
+			case "hanging":
+				return &hangingMCPClient{MockMCPClient: createMockClient()}, nil
+			case "unreachable":
+				return &unreachableMCPClient{
+					MockMCPClient: createMockClient(),
+					closed:        make(chan struct{}),
+				}, nil
+			default:
+				return createMockClient(), nil
+			}
+		}),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	serverConfigs := []config.Server{
+		{Name: "hanging", Type: config.ServerTypeStdio},
+		{Name: "unreachable", Type: config.ServerTypeSSE},
+		{Name: "first", Type: config.ServerTypeStdio},
+		{Name: "second", Type: config.ServerTypeStdio},
+	}
+
+	start := time.Now()
+	errs := interposerInstance.StartBackends(context.Background(), serverConfigs, 50*time.Millisecond)
+
+	// Stuck backends time out on their own without holding up the others
+	assert.Less(t, time.Since(start), time.Second)
+	require.Len(t, errs, 2)
+	require.ErrorIs(t, errs["hanging"], context.DeadlineExceeded)
+	require.ErrorIs(t, errs["unreachable"], context.DeadlineExceeded)
+
+	assert.True(t, interposerInstance.verifyClientExists("first"))
+	assert.True(t, interposerInstance.verifyClientExists("second"))
+	assert.False(t, interposerInstance.verifyClientExists("hanging"))
+	assert.False(t, interposerInstance.verifyClientExists("unreachable"))
+}
+
+func TestStartBackendsDeadline(t *testing.T) {
+	t.Parallel()
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(func(_ config.Server) (client.MCPClient, error) {
+			return &hangingMCPClient{MockMCPClient: createMockClient()}, nil
+		}),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// The startup deadline applies even without a per backend timeout
+	errs := interposerInstance.StartBackends(ctx, []config.Server{
+		{Name: "hanging", Type: config.ServerTypeStdio},
+	}, 0)
+
+	require.ErrorIs(t, errs["hanging"], context.DeadlineExceeded)
+}
@@ -0,0 +1,481 @@
+package interposer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+const (
+	// methodResourcesSubscribe asks to be notified of a resource's updates.
+	methodResourcesSubscribe = "resources/subscribe"
+
+	// methodResourcesUnsubscribe stops notifying of a resource's updates.
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+)
+
+// ErrUnknownResource is returned for a subscription to a resource no backend provides.
+var ErrUnknownResource = errors.New("resource not provided by any backend")
+
+// subscription is a resource of a backend that clients are subscribed to,
+// by the URI of the backend.
+type subscription struct {
+	backend string
+	uri     string
+}
+
+// pendingSubscription is the subscription of a backend to a resource in
+// progress, which sessions subscribing meanwhile wait for.
+type pendingSubscription struct {
+	done chan struct{}
+	err  error
+}
+
+// wait returns the error subscribing the backend once done.
+func (p *pendingSubscription) wait(ctx context.Context) error {
+	select {
+	case <-p.done:
+		return p.err
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for subscription: %w", ctx.Err())
+	}
+}
+
+// subscriptions tracks the resources each client session is subscribed to.
+// A backend is subscribed to a resource once, for as long as any session is.
+type subscriptions struct {
+	mu         sync.Mutex // protects sessions, subscribed and pending
+	sessions   map[string]struct{}
+	subscribed map[subscription]map[string]struct{}
+	pending    map[subscription]*pendingSubscription
+	released   func(subs []subscription)
+}
+
+// newSubscriptions creates a new subscription tracker, calling released with
+// the resources no session is subscribed to anymore once a session ends.
+func newSubscriptions(released func(subs []subscription)) *subscriptions {
+	return &subscriptions{
+		sessions:   make(map[string]struct{}),
+		subscribed: make(map[subscription]map[string]struct{}),
+		pending:    make(map[subscription]*pendingSubscription),
+		released:   released,
+	}
+}
+
+// hooks adds the server hooks tracking the client sessions.
+func (s *subscriptions) hooks(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(func(_ context.Context, session server.ClientSession) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.sessions[session.SessionID()] = struct{}{}
+	})
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		if released := s.end(session.SessionID()); len(released) > 0 {
+			s.released(released)
+		}
+	})
+}
+
+// connected returns true if the session is connected.
+func (s *subscriptions) connected(sessionID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, exists := s.sessions[sessionID]
+
+	return exists
+}
+
+// add subscribes a session to a resource, returning true if it is the first,
+// which then subscribes the backend and reports the outcome with finish.
+// Sessions subscribing while the backend is being subscribed are returned
+// the pending subscription to wait for, nil once the backend is subscribed.
+func (s *subscriptions) add(sessionID string, sub subscription) (*pendingSubscription, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, exists := s.subscribed[sub]
+	if !exists {
+		sessions = make(map[string]struct{})
+		s.subscribed[sub] = sessions
+	}
+
+	sessions[sessionID] = struct{}{}
+
+	if pending, waiting := s.pending[sub]; waiting {
+		return pending, false
+	}
+
+	if exists {
+		return nil, false
+	}
+
+	s.pending[sub] = &pendingSubscription{done: make(chan struct{})}
+
+	return nil, true
+}
+
+// finish ends subscribing the backend to a resource. If it failed, every
+// session that subscribed meanwhile is unsubscribed.
+func (s *subscriptions) finish(sub subscription, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, exists := s.pending[sub]
+	if !exists {
+		return
+	}
+
+	delete(s.pending, sub)
+
+	if err != nil {
+		delete(s.subscribed, sub)
+	}
+
+	pending.err = err
+	close(pending.done)
+}
+
+// remove unsubscribes a session from a resource, returning true if it was
+// the last one.
+func (s *subscriptions) remove(sessionID string, sub subscription) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, exists := s.subscribed[sub]
+	if !exists {
+		return false
+	}
+
+	delete(sessions, sessionID)
+
+	if len(sessions) > 0 {
+		return false
+	}
+
+	delete(s.subscribed, sub)
+
+	return true
+}
+
+// end forgets a session, returning the resources it was the last one subscribed to.
+func (s *subscriptions) end(sessionID string) []subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, sessionID)
+
+	var released []subscription
+
+	for sub, sessions := range s.subscribed {
+		if _, exists := sessions[sessionID]; !exists {
+			continue
+		}
+
+		delete(sessions, sessionID)
+
+		if len(sessions) == 0 {
+			delete(s.subscribed, sub)
+			released = append(released, sub)
+		}
+	}
+
+	return released
+}
+
+// subscribers returns the sessions subscribed to a resource.
+func (s *subscriptions) subscribers(sub subscription) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Collect(maps.Keys(s.subscribed[sub]))
+}
+
+// backend returns the resources of a backend sessions are subscribed to.
+func (s *subscriptions) backend(name string) []subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var subs []subscription
+
+	for sub := range s.subscribed {
+		if sub.backend == name {
+			subs = append(subs, sub)
+		}
+	}
+
+	return subs
+}
+
+// active returns true if sessions are subscribed to resources of a backend.
+func (s *subscriptions) active(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subscribed {
+		if sub.backend == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// forget drops the subscriptions to the resources of a backend.
+func (s *subscriptions) forget(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subscribed {
+		if sub.backend == name {
+			delete(s.subscribed, sub)
+		}
+	}
+}
+
+// Intercept answers the resources/subscribe and resources/unsubscribe
+// requests of client sessions, which the MCP server does not handle. Other
+// messages are left to the server. It implements the serve.Interceptor type.
+func (i *Interposer) Intercept(
+	ctx context.Context,
+	sessionID string,
+	message json.RawMessage,
+) (func() mcp.JSONRPCMessage, bool) {
+	var request struct {
+		ID     mcp.RequestId `json:"id"`
+		Method string        `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+
+	if err := json.Unmarshal(message, &request); err != nil || request.ID.IsNil() {
+		return nil, false
+	}
+
+	// Requests of unknown sessions are left for the server to reject
+	if !i.subscriptions.connected(sessionID) {
+		return nil, false
+	}
+
+	var handle func(ctx context.Context, sessionID, uri string) error
+
+	switch request.Method {
+	case methodResourcesSubscribe:
+		handle = i.subscribe
+	case methodResourcesUnsubscribe:
+		handle = i.unsubscribe
+	default:
+		return nil, false
+	}
+
+	return func() mcp.JSONRPCMessage {
+		err := handle(ctx, sessionID, request.Params.URI)
+
+		switch {
+		case errors.Is(err, ErrUnknownResource):
+			return mcp.NewJSONRPCError(request.ID, mcp.RESOURCE_NOT_FOUND, err.Error(), nil)
+		case err != nil:
+			return mcp.NewJSONRPCError(request.ID, mcp.INTERNAL_ERROR, err.Error(), nil)
+		default:
+			return mcp.NewJSONRPCResultResponse(request.ID, mcp.EmptyResult{})
+		}
+	}, true
+}
+
+// subscribe subscribes a session to a resource, subscribing its backend to
+// it for the first session.
+func (i *Interposer) subscribe(ctx context.Context, sessionID, uri string) error {
+	sub, cfg, mcpClient, err := i.resolveSubscription(uri)
+	if err != nil {
+		return err
+	}
+
+	pending, first := i.subscriptions.add(sessionID, sub)
+	if !first {
+		return i.awaitSubscription(ctx, sessionID, uri, sub, pending)
+	}
+
+	ctx, cancel := withTimeout(ctx, cfg.Timeouts.Read)
+	defer cancel()
+
+	request := mcp.SubscribeRequest{}
+	request.Params.URI = sub.uri
+
+	err = mcpClient.Subscribe(ctx, request)
+	i.subscriptions.finish(sub, err)
+
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", uri, err)
+	}
+
+	return nil
+}
+
+// awaitSubscription waits for the backend being subscribed to a resource by
+// another session. A session giving up on waiting is unsubscribed again.
+func (i *Interposer) awaitSubscription(
+	ctx context.Context,
+	sessionID, uri string,
+	sub subscription,
+	pending *pendingSubscription,
+) error {
+	if pending == nil {
+		return nil
+	}
+
+	err := pending.wait(ctx)
+	if err == nil {
+		return nil
+	}
+
+	if ctx.Err() != nil && i.subscriptions.remove(sessionID, sub) {
+		i.releaseSubscriptions([]subscription{sub})
+	}
+
+	return fmt.Errorf("failed to subscribe to %s: %w", uri, err)
+}
+
+// unsubscribe unsubscribes a session from a resource, unsubscribing its
+// backend from it for the last session.
+func (i *Interposer) unsubscribe(ctx context.Context, sessionID, uri string) error {
+	sub, cfg, mcpClient, err := i.resolveSubscription(uri)
+	if err != nil {
+		return err
+	}
+
+	if !i.subscriptions.remove(sessionID, sub) {
+		return nil
+	}
+
+	ctx, cancel := withTimeout(ctx, cfg.Timeouts.Read)
+	defer cancel()
+
+	request := mcp.UnsubscribeRequest{}
+	request.Params.URI = sub.uri
+
+	if err := mcpClient.Unsubscribe(ctx, request); err != nil {
+		return fmt.Errorf("failed to unsubscribe from %s: %w", uri, err)
+	}
+
+	return nil
+}
+
+// resolveSubscription returns the backend providing a resource clients see,
+// with the resource's URI on the backend.
+func (i *Interposer) resolveSubscription(uri string) (subscription, config.Server, client.MCPClient, error) {
+	name, exists := i.resourceBackend(uri)
+	if !exists {
+		return subscription{}, config.Server{}, nil, fmt.Errorf("%w: %s", ErrUnknownResource, uri)
+	}
+
+	i.mu.RLock()
+	mcpClient, connected := i.clients[name]
+	cfg := i.configs[name]
+	i.mu.RUnlock()
+
+	if !connected {
+		return subscription{}, config.Server{}, nil, fmt.Errorf("%w: %s", ErrBackendNotFound, name)
+	}
+
+	return subscription{backend: name, uri: cfg.OriginalURI(uri)}, cfg, mcpClient, nil
+}
+
+// resourceBackend returns the backend providing a resource clients see,
+// either a listed resource or one from a template, named `backend+uri`.
+func (i *Interposer) resourceBackend(uri string) (string, bool) {
+	if capName, exists := i.catalog.findURI("resource", uri); exists {
+		if name, found := i.registry.GetBackendForCapability("resource", capName); found {
+			return name, true
+		}
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	for name, cfg := range i.configs {
+		if prefix := cfg.NamePrefix(); prefix != "" && strings.HasPrefix(uri, prefix+config.URISeparator) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// releaseSubscriptions unsubscribes the backends from the resources no
+// session is subscribed to anymore.
+func (i *Interposer) releaseSubscriptions(subs []subscription) {
+	for _, sub := range subs {
+		i.mu.RLock()
+		mcpClient, exists := i.clients[sub.backend]
+		i.mu.RUnlock()
+
+		if !exists {
+			continue
+		}
+
+		request := mcp.UnsubscribeRequest{}
+		request.Params.URI = sub.uri
+
+		if err := mcpClient.Unsubscribe(i.ctx, request); err != nil {
+			log.Printf("Warning: failed to unsubscribe %s from %s: %v", sub.backend, sub.uri, err)
+		}
+	}
+}
+
+// resubscribe subscribes a backend that was restarted again to the resources
+// clients are subscribed to.
+func (i *Interposer) resubscribe(ctx context.Context, name string, mcpClient client.MCPClient) {
+	for _, sub := range i.subscriptions.backend(name) {
+		request := mcp.SubscribeRequest{}
+		request.Params.URI = sub.uri
+
+		if err := mcpClient.Subscribe(ctx, request); err != nil {
+			log.Printf("Warning: failed to resubscribe %s to %s: %v", name, sub.uri, err)
+		}
+	}
+}
+
+// watchSubscriptions forwards the resource update notifications of a backend
+// to the sessions subscribed to the resource, with the URI they see.
+func (i *Interposer) watchSubscriptions(name string, mcpClient client.MCPClient) {
+	mcpClient.OnNotification(func(notification mcp.JSONRPCNotification) {
+		if notification.Method != mcp.MethodNotificationResourceUpdated {
+			return
+		}
+
+		uri, _ := notification.Params.AdditionalFields["uri"].(string)
+		sessions := i.subscriptions.subscribers(subscription{backend: name, uri: uri})
+
+		if len(sessions) == 0 {
+			return
+		}
+
+		i.mu.RLock()
+		cfg := i.configs[name]
+		i.mu.RUnlock()
+
+		params := maps.Clone(notification.Params.AdditionalFields)
+		params["uri"] = cfg.ExposedURI(uri)
+
+		for _, sessionID := range sessions {
+			err := i.server.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, params)
+			if err != nil {
+				log.Printf("Warning: failed to forward update of %s from %s: %v", uri, name, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,327 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package interposer
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// subscriptionClient is a mock client recording the resources it is
+// subscribed to.
+type subscriptionClient struct {
+	*progressClient
+
+	subscribed []string
+}
+
+// Subscribe implements the Subscribe method of the MCPClient interface.
+func (c *subscriptionClient) Subscribe(_ context.Context, request mcp.SubscribeRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.subscribed = append(c.subscribed, request.Params.URI)
+
+	return nil
+}
+
+// Unsubscribe implements the Unsubscribe method of the MCPClient interface.
+func (c *subscriptionClient) Unsubscribe(_ context.Context, request mcp.UnsubscribeRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for index, uri := range c.subscribed {
+		if uri == request.Params.URI {
+			c.subscribed = append(c.subscribed[:index], c.subscribed[index+1:]...)
+
+			break
+		}
+	}
+
+	return nil
+}
+
+// subscriptions returns the resources the client is subscribed to.
+func (c *subscriptionClient) subscriptions() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.subscribed...)
+}
+
+// interceptRequest sends a request about a resource of a session to the interposer.
+func interceptRequest(
+	t *testing.T,
+	interposerInstance *Interposer,
+	sessionID, method, uri string,
+) (mcp.JSONRPCMessage, bool) {
+	t.Helper()
+
+	message, err := json.Marshal(map[string]any{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      1,
+		"method":  method,
+		"params":  map[string]any{"uri": uri},
+	})
+	require.NoError(t, err)
+
+	answer, ok := interposerInstance.Intercept(context.Background(), sessionID, message)
+	if !ok {
+		return nil, false
+	}
+
+	return answer(), true
+}
+
+func TestResourceSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	backend := &subscriptionClient{progressClient: &progressClient{MockMCPClient: createMockClient()}}
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(func(config.Server) (client.MCPClient, error) { return backend, nil }),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	require.NoError(t, interposerInstance.AddBackend(
+		context.Background(),
+		"backend",
+		config.Server{Name: "backend", Type: config.ServerTypeStdio},
+	))
+
+	mcpServer := interposerInstance.Server()
+	first, second := newFakeSession("first"), newFakeSession("second")
+
+	for _, session := range []*fakeSession{first, second} {
+		require.NoError(t, mcpServer.RegisterSession(context.Background(), session))
+	}
+
+	request := func(sessionID, method, uri string) (mcp.JSONRPCMessage, bool) {
+		return interceptRequest(t, interposerInstance, sessionID, method, uri)
+	}
+
+	subscribe := func(sessionID, uri string) {
+		response, handled := request(sessionID, methodResourcesSubscribe, uri)
+		require.True(t, handled)
+		require.IsType(t, mcp.JSONRPCResponse{}, response)
+	}
+
+	// The backend is subscribed once, by its own URIs
+	subscribe("first", "backend+test://resource")
+	subscribe("second", "backend+test://resource")
+	subscribe("first", "backend+test://42")
+
+	assert.Equal(t, []string{"test://resource", "test://42"}, backend.subscriptions())
+
+	// Updates reach the subscribed sessions with the URI they see
+	backend.notify(mcp.MethodNotificationResourceUpdated, map[string]any{"uri": "test://resource"})
+	backend.notify(mcp.MethodNotificationResourceUpdated, map[string]any{"uri": "test://other"})
+
+	for _, session := range []*fakeSession{first, second} {
+		require.Len(t, session.notifications, 1)
+
+		notification := <-session.notifications
+		assert.Equal(t, mcp.MethodNotificationResourceUpdated, notification.Method)
+		assert.Equal(t, "backend+test://resource", notification.Params.AdditionalFields["uri"])
+	}
+
+	// Resources no backend provides are not found
+	response, handled := request("first", methodResourcesSubscribe, "other+test://resource")
+	require.True(t, handled)
+
+	rpcError, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "subscribing should fail, got %#v", response)
+	assert.Equal(t, mcp.RESOURCE_NOT_FOUND, rpcError.Error.Code)
+
+	// Other requests and the requests of unknown sessions are left to the server
+	_, handled = request("first", string(mcp.MethodResourcesRead), "backend+test://resource")
+	assert.False(t, handled)
+
+	_, handled = request("unknown", methodResourcesSubscribe, "backend+test://resource")
+	assert.False(t, handled)
+
+	// The backend stays subscribed while any session is
+	response, handled = request("first", methodResourcesUnsubscribe, "backend+test://resource")
+	require.True(t, handled)
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+
+	assert.Equal(t, []string{"test://resource", "test://42"}, backend.subscriptions())
+
+	// Subscriptions end with the session
+	mcpServer.UnregisterSession(context.Background(), "second")
+	assert.Equal(t, []string{"test://42"}, backend.subscriptions())
+
+	mcpServer.UnregisterSession(context.Background(), "first")
+	assert.Empty(t, backend.subscriptions())
+}
+
+func TestIdleBackendKeepsSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	backends := make(chan *subscriptionClient, 10)
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(func(config.Server) (client.MCPClient, error) {
+			backend := &subscriptionClient{progressClient: &progressClient{MockMCPClient: createMockClient()}}
+			backends <- backend
+
+			return backend, nil
+		}),
+		WithManifestStore(nil),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	require.NoError(t, interposerInstance.AddBackend(
+		context.Background(),
+		"backend",
+		config.Server{Name: "backend", Type: config.ServerTypeStdio, IdleTimeout: config.Duration(10 * time.Millisecond)},
+	))
+
+	backend := <-backends
+
+	interposerInstance.mu.RLock()
+	supervised, ok := interposerInstance.clients["backend"].(*supervisedClient)
+	interposerInstance.mu.RUnlock()
+	require.True(t, ok)
+
+	lazy, ok := supervised.MCPClient.(*lazyClient)
+	require.True(t, ok)
+
+	running := func() bool {
+		current, _ := lazy.started()
+
+		return current != nil
+	}
+
+	session := newFakeSession("first")
+	require.NoError(t, interposerInstance.Server().RegisterSession(context.Background(), session))
+
+	const uri = "backend+test://resource"
+
+	response, handled := interceptRequest(t, interposerInstance, "first", methodResourcesSubscribe, uri)
+	require.True(t, handled)
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+
+	// The subscribed backend keeps running to send updates
+	assert.Never(t, func() bool { return !running() }, 100*time.Millisecond, time.Millisecond)
+	assert.Empty(t, backends, "the backend should not have been restarted")
+	assert.Equal(t, []string{"test://resource"}, backend.subscriptions())
+
+	backend.notify(mcp.MethodNotificationResourceUpdated, map[string]any{"uri": "test://resource"})
+	require.Len(t, session.notifications, 1)
+
+	// Once unsubscribed it is stopped when idle
+	response, handled = interceptRequest(t, interposerInstance, "first", methodResourcesUnsubscribe, uri)
+	require.True(t, handled)
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+
+	require.Eventually(t, func() bool { return !running() }, time.Second, time.Millisecond)
+}
+
+// slowSubscriptionClient is a mock client whose subscriptions complete with
+// the errors sent to it.
+type slowSubscriptionClient struct {
+	*MockMCPClient
+
+	calls   atomic.Int32
+	results chan error
+}
+
+// Subscribe implements the Subscribe method of the MCPClient interface.
+func (c *slowSubscriptionClient) Subscribe(ctx context.Context, _ mcp.SubscribeRequest) error {
+	c.calls.Add(1)
+
+	select {
+	case err := <-c.results:
+		return err
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck // Returned for inspection
+	}
+}
+
+func TestPendingSubscription(t *testing.T) {
+	t.Parallel()
+
+	backend := &slowSubscriptionClient{MockMCPClient: createMockClient(), results: make(chan error)}
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(func(config.Server) (client.MCPClient, error) { return backend, nil }),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	require.NoError(t, interposerInstance.AddBackend(
+		context.Background(),
+		"backend",
+		config.Server{Name: "backend", Type: config.ServerTypeStdio},
+	))
+
+	for _, sessionID := range []string{"first", "second"} {
+		require.NoError(t, interposerInstance.Server().RegisterSession(context.Background(), newFakeSession(sessionID)))
+	}
+
+	const uri = "backend+test://resource"
+
+	// subscribeBoth subscribes both sessions while the backend's
+	// subscription is pending, completing it with the error
+	subscribeBoth := func(result error) []error {
+		errs := make(chan error, 2)
+
+		for _, sessionID := range []string{"first", "second"} {
+			go func() {
+				errs <- interposerInstance.subscribe(context.Background(), sessionID, uri)
+			}()
+
+			require.Eventually(t, func() bool {
+				return len(interposerInstance.subscriptions.subscribers(subscription{"backend", "test://resource"})) > 0
+			}, time.Second, time.Millisecond)
+		}
+
+		require.Eventually(t, func() bool {
+			return len(interposerInstance.subscriptions.subscribers(subscription{"backend", "test://resource"})) == 2
+		}, time.Second, time.Millisecond)
+
+		backend.results <- result
+
+		return []error{<-errs, <-errs}
+	}
+
+	// A failed subscription fails and unsubscribes every waiting session
+	for _, err := range subscribeBoth(assert.AnError) {
+		require.ErrorIs(t, err, assert.AnError)
+	}
+
+	assert.False(t, interposerInstance.subscriptions.active("backend"))
+	assert.Equal(t, int32(1), backend.calls.Load())
+
+	// A successful one subscribes the backend once for both
+	for _, err := range subscribeBoth(nil) {
+		require.NoError(t, err)
+	}
+
+	assert.ElementsMatch(t,
+		[]string{"first", "second"},
+		interposerInstance.subscriptions.subscribers(subscription{"backend", "test://resource"}),
+	)
+	assert.Equal(t, int32(2), backend.calls.Load())
+}
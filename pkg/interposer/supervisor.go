@@ -0,0 +1,315 @@
+package interposer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// connectionLostNotifier is implemented by clients that report a lost connection.
+type connectionLostNotifier interface {
+	OnConnectionLost(handler func(error))
+}
+
+// isConnectionLost returns true if the error means the backend is gone,
+// as opposed to a request that failed.
+func isConnectionLost(err error) bool {
+	return errors.Is(err, transport.ErrTransportClosed) ||
+		errors.Is(err, transport.ErrSessionTerminated) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, os.ErrClosed) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
+
+// backoffDelay returns the delay before the given reconnect attempt,
+// growing exponentially up to the maximum and randomized by the jitter.
+func backoffDelay(backoff config.Backoff, attempt int) time.Duration {
+	delay := float64(backoff.Initial)
+
+	for range attempt - 1 {
+		delay *= backoff.Multiplier
+		if delay >= float64(backoff.Max) {
+			delay = float64(backoff.Max)
+
+			break
+		}
+	}
+
+	if backoff.Jitter == nil {
+		return time.Duration(delay)
+	}
+
+	//nolint:gosec // Jitter does not need a cryptographic random source
+	jitter := delay * *backoff.Jitter * (rand.Float64()*2 - 1)
+
+	return time.Duration(delay + jitter)
+}
+
+// supervisedClient reports requests failing because the backend died and
+// short-circuits requests while the backend is unhealthy.
+type supervisedClient struct {
+	client.MCPClient
+
+	lost    func(error)
+	healthy func() error
+}
+
+// check reports the error if it means the backend died.
+func (c *supervisedClient) check(err error) error {
+	if err != nil && isConnectionLost(err) {
+		c.lost(err)
+	}
+
+	return err
+}
+
+// Ping implements the client.MCPClient interface.
+func (c *supervisedClient) Ping(ctx context.Context) error {
+	return c.check(c.MCPClient.Ping(ctx))
+}
+
+// CallTool implements the client.MCPClient interface.
+func (c *supervisedClient) CallTool(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	if err := c.healthy(); err != nil {
+		return nil, err
+	}
+
+	result, err := c.MCPClient.CallTool(ctx, request)
+
+	return result, c.check(err)
+}
+
+// GetPrompt implements the client.MCPClient interface.
+func (c *supervisedClient) GetPrompt(
+	ctx context.Context,
+	request mcp.GetPromptRequest,
+) (*mcp.GetPromptResult, error) {
+	if err := c.healthy(); err != nil {
+		return nil, err
+	}
+
+	result, err := c.MCPClient.GetPrompt(ctx, request)
+
+	return result, c.check(err)
+}
+
+// ReadResource implements the client.MCPClient interface.
+func (c *supervisedClient) ReadResource(
+	ctx context.Context,
+	request mcp.ReadResourceRequest,
+) (*mcp.ReadResourceResult, error) {
+	if err := c.healthy(); err != nil {
+		return nil, err
+	}
+
+	result, err := c.MCPClient.ReadResource(ctx, request)
+
+	return result, c.check(err)
+}
+
+// RootListChanges implements the rootsNotifier interface.
+func (c *supervisedClient) RootListChanges(ctx context.Context) error {
+	notifier, ok := c.MCPClient.(rootsNotifier)
+	if !ok {
+		return nil
+	}
+
+	return c.check(notifier.RootListChanges(ctx))
+}
+
+// supervise wraps the client of a backend so that its death is detected,
+// either from failing requests or from the transport reporting a lost connection,
+// and so that it is not sent requests while unhealthy.
+func (i *Interposer) supervise(name string, mcpClient client.MCPClient) client.MCPClient {
+	supervised := &supervisedClient{MCPClient: mcpClient}
+	supervised.lost = func(err error) {
+		i.backendLost(name, supervised, err)
+	}
+	supervised.healthy = func() error {
+		return i.checkHealth(name)
+	}
+
+	if notifier, ok := mcpClient.(connectionLostNotifier); ok {
+		notifier.OnConnectionLost(supervised.lost)
+	}
+
+	return supervised
+}
+
+// backendLost removes the capabilities of a backend that died and schedules
+// reconnecting to it. Reports for a client that was already replaced are ignored.
+func (i *Interposer) backendLost(name string, lost client.MCPClient, err error) {
+	i.mu.Lock()
+
+	current, exists := i.clients[name]
+	if !exists || current != lost {
+		i.mu.Unlock()
+
+		return
+	}
+
+	delete(i.clients, name)
+	delete(i.results, name)
+	cfg := i.configs[name]
+
+	i.mu.Unlock()
+
+	log.Printf("Backend %s died: %v", name, err)
+
+	if err := lost.Close(); err != nil {
+		log.Printf("Error closing client %s: %v", name, err)
+	}
+
+	i.RemoveTrackedCapabilities(i.ctx, name)
+	i.registry.RemoveBackendHealth(name)
+	i.scheduleReconnect(name, cfg)
+}
+
+// pendingReconnect is a reconnect running in the background.
+type pendingReconnect struct {
+	cancel context.CancelFunc
+}
+
+// scheduleReconnect starts reconnecting to a backend in the background,
+// replacing any reconnect already pending for it.
+func (i *Interposer) scheduleReconnect(name string, cfg config.Server) {
+	ctx, cancel := context.WithCancel(i.ctx)
+	pending := &pendingReconnect{cancel: cancel}
+
+	i.reconnectsMu.Lock()
+	if previous, exists := i.reconnects[name]; exists {
+		previous.cancel()
+	}
+
+	i.reconnects[name] = pending
+	i.reconnectsMu.Unlock()
+
+	go i.reconnect(ctx, pending, name, cfg)
+}
+
+// cancelReconnect stops a pending reconnect of a backend.
+func (i *Interposer) cancelReconnect(name string) {
+	i.reconnectsMu.Lock()
+	defer i.reconnectsMu.Unlock()
+
+	if pending, exists := i.reconnects[name]; exists {
+		pending.cancel()
+		delete(i.reconnects, name)
+	}
+}
+
+// finishReconnect forgets a reconnect unless it has been replaced.
+func (i *Interposer) finishReconnect(name string, pending *pendingReconnect) {
+	i.reconnectsMu.Lock()
+	defer i.reconnectsMu.Unlock()
+
+	pending.cancel()
+
+	if i.reconnects[name] == pending {
+		delete(i.reconnects, name)
+	}
+}
+
+// reconnect re-adds a backend with exponential backoff until it succeeds,
+// the attempts are exhausted, or the reconnect is canceled.
+func (i *Interposer) reconnect(
+	ctx context.Context,
+	pending *pendingReconnect,
+	name string,
+	cfg config.Server,
+) {
+	defer i.finishReconnect(name, pending)
+
+	backoff := cfg.Backoff.WithDefaults()
+
+	for attempt := 1; backoff.Attempts == 0 || attempt <= backoff.Attempts; attempt++ {
+		delay := backoffDelay(backoff, attempt)
+		log.Printf("Reconnecting to %s in %s (attempt %d)", name, delay, attempt)
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+		}
+
+		if i.reconnectAttempt(ctx, name, cfg) {
+			return
+		}
+	}
+
+	log.Printf("Giving up reconnecting to %s after %d attempts", name, backoff.Attempts)
+}
+
+// reconnectAttempt tries to re-add a backend once. Returns true when no
+// further attempts are needed.
+func (i *Interposer) reconnectAttempt(ctx context.Context, name string, cfg config.Server) bool {
+	i.lifecycle.Lock()
+	defer i.lifecycle.Unlock()
+
+	// The backend was reconfigured or removed while waiting
+	if ctx.Err() != nil {
+		return true
+	}
+
+	// The client outlives this reconnect, so it is bound to the interposer
+	if err := i.AddBackend(i.ctx, name, cfg); err != nil {
+		log.Printf("Warning: failed to reconnect to %s: %v", name, err)
+
+		return false
+	}
+
+	log.Printf("Reconnected to %s", name)
+
+	// Capabilities are back, let clients know
+	i.notifyBackendCapabilities(name)
+
+	return true
+}
+
+// processReconnectingBackends restarts pending reconnects with the new
+// configuration, or cancels them if the backend is no longer configured.
+// Rescheduled backends are removed from the new config map.
+func (i *Interposer) processReconnectingBackends(newConfigMap map[string]config.Server) {
+	i.reconnectsMu.Lock()
+
+	names := make([]string, 0, len(i.reconnects))
+	for name := range i.reconnects {
+		names = append(names, name)
+	}
+
+	i.reconnectsMu.Unlock()
+
+	for _, name := range names {
+		newConfig, exists := newConfigMap[name]
+		if !exists {
+			log.Printf("Backend %s is no longer configured, stopping reconnect", name)
+			i.cancelReconnect(name)
+
+			continue
+		}
+
+		i.scheduleReconnect(name, newConfig)
+		delete(newConfigMap, name)
+	}
+}
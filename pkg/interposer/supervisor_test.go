@@ -0,0 +1,180 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package interposer
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// deadMCPClient is a mock client whose backend has died.
+type deadMCPClient struct {
+	*MockMCPClient
+}
+
+// CallTool implements the CallTool method of the MCPClient interface.
+func (m *deadMCPClient) CallTool(
+	_ context.Context,
+	_ mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	return nil, transport.NewError(transport.ErrTransportClosed)
+}
+
+// flakyClientFactory returns a factory whose first client is dead,
+// and counts the clients it created.
+func flakyClientFactory(created *atomic.Int32) func(config.Server) (client.MCPClient, error) {
+	return func(_ config.Server) (client.MCPClient, error) {
+		if created.Add(1) == 1 {
+			return &deadMCPClient{MockMCPClient: createMockClient()}, nil
+		}
+
+		return createMockClient(), nil
+	}
+}
+
+func callTool(t *testing.T, interposerInstance *Interposer, name string) error {
+	t.Helper()
+
+	tool := interposerInstance.Server().GetTool(name)
+	require.NotNil(t, tool, "tool %s should be registered", name)
+
+	_, err := tool.Handler(context.Background(), mcp.CallToolRequest{})
+
+	return err //nolint:wrapcheck // Returned for inspection
+}
+
+func TestSupervisorReconnectsDeadBackend(t *testing.T) {
+	t.Parallel()
+
+	var created atomic.Int32
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(flakyClientFactory(&created)),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	serverConfig := config.Server{
+		Name:    "test-server",
+		Type:    config.ServerTypeStdio,
+		Backoff: &config.Backoff{Initial: config.Duration(time.Millisecond)},
+	}
+
+	require.NoError(t, interposerInstance.AddBackend(context.Background(), "test-server", serverConfig))
+
+	// The call fails and the dead backend's capabilities are removed
+	require.Error(t, callTool(t, interposerInstance, "test-server-test-tool"))
+
+	// The backend comes back with its capabilities
+	require.Eventually(t, func() bool {
+		return interposerInstance.verifyClientExists("test-server")
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(t, int32(2), created.Load())
+	require.NoError(t, callTool(t, interposerInstance, "test-server-test-tool"))
+
+	backend, exists := interposerInstance.registry.GetBackendForCapability("tool", "test-server-test-tool")
+	assert.True(t, exists)
+	assert.Equal(t, "test-server", backend)
+}
+
+func TestSupervisorStopsWhenBackendRemoved(t *testing.T) {
+	t.Parallel()
+
+	var created atomic.Int32
+
+	interposerInstance, err := NewInterposer(
+		"TestInterposer",
+		"1.0.0",
+		WithClientFactory(flakyClientFactory(&created)),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = interposerInstance.Close() })
+
+	serverConfig := config.Server{
+		Name:    "test-server",
+		Type:    config.ServerTypeStdio,
+		Backoff: &config.Backoff{Initial: config.Duration(time.Hour)},
+	}
+
+	ctx := context.Background()
+
+	require.NoError(t, interposerInstance.AddBackend(ctx, "test-server", serverConfig))
+	require.Error(t, callTool(t, interposerInstance, "test-server-test-tool"))
+
+	assert.False(t, interposerInstance.verifyClientExists("test-server"))
+	assert.Empty(t, interposerInstance.registry.GetCapabilitiesForBackend("test-server"))
+
+	interposerInstance.reconnectsMu.Lock()
+	assert.Contains(t, interposerInstance.reconnects, "test-server")
+	interposerInstance.reconnectsMu.Unlock()
+
+	// Removing the backend from the config cancels the reconnect
+	require.NoError(t, interposerInstance.Reconfigure(ctx, nil))
+
+	interposerInstance.reconnectsMu.Lock()
+	assert.Empty(t, interposerInstance.reconnects)
+	interposerInstance.reconnectsMu.Unlock()
+
+	assert.Equal(t, int32(1), created.Load())
+}
+
+func TestSupervisorIgnoresFailedRequests(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isConnectionLost(transport.NewError(transport.ErrTransportClosed)))
+	assert.True(t, isConnectionLost(fmt.Errorf("failed: %w", transport.ErrSessionTerminated)))
+	assert.False(t, isConnectionLost(context.DeadlineExceeded))
+	assert.False(t, isConnectionLost(fmt.Errorf("request failed: %w", transport.ErrUnauthorized)))
+}
+
+func TestBackoffDelay(t *testing.T) {
+	t.Parallel()
+
+	jitter := 0.1
+	backoff := config.Backoff{
+		Initial:    config.Duration(time.Second),
+		Max:        config.Duration(10 * time.Second),
+		Multiplier: 2,
+		Jitter:     &jitter,
+	}
+
+	within := func(expected time.Duration, actual time.Duration) {
+		t.Helper()
+
+		assert.InDelta(t, float64(expected), float64(actual), float64(expected)*jitter)
+	}
+
+	within(time.Second, backoffDelay(backoff, 1))
+	within(2*time.Second, backoffDelay(backoff, 2))
+	within(8*time.Second, backoffDelay(backoff, 4))
+	within(10*time.Second, backoffDelay(backoff, 10))
+
+	defaults := (*config.Backoff)(nil).WithDefaults()
+	assert.Equal(t, config.Duration(config.DefaultBackoffInitial), defaults.Initial)
+	assert.Equal(t, config.Duration(config.DefaultBackoffMax), defaults.Max)
+	assert.InDelta(t, config.DefaultBackoffJitter, *defaults.Jitter, 0)
+
+	// Jitter can be turned off for exact delays
+	none := 0.0
+	exact := (&config.Backoff{Initial: config.Duration(time.Second), Jitter: &none}).WithDefaults()
+	assert.Equal(t, 2*time.Second, backoffDelay(exact, 2))
+
+	// Out of range jitter falls back to the default
+	invalid := 1.5
+	assert.InDelta(t, config.DefaultBackoffJitter, *(&config.Backoff{Jitter: &invalid}).WithDefaults().Jitter, 0)
+}
@@ -0,0 +1,104 @@
+package isolate
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// MethodNotificationCancelled is sent to cancel a request.
+	MethodNotificationCancelled = "notifications/cancelled"
+
+	// cancelTimeout bounds sending the cancellation of a request.
+	cancelTimeout = 5 * time.Second
+)
+
+// cancellingTransport tells the server when a request is abandoned, by
+// sending notifications/cancelled for requests whose context is done before
+// the response arrives, so that the server can stop working on them.
+type cancellingTransport struct {
+	transport.Interface
+}
+
+// newCancellingTransport wraps a transport to cancel abandoned requests.
+func newCancellingTransport(inner transport.Interface) *cancellingTransport {
+	return &cancellingTransport{Interface: inner}
+}
+
+// SendRequest sends a request, cancelling it if ctx is done first.
+func (t *cancellingTransport) SendRequest(
+	ctx context.Context,
+	request transport.JSONRPCRequest,
+) (*transport.JSONRPCResponse, error) {
+	response, err := t.Interface.SendRequest(ctx, request)
+	if err == nil || ctx.Err() == nil {
+		return response, err //nolint:wrapcheck // Transparent wrapper
+	}
+
+	// The initialize request must not be cancelled
+	if request.Method != string(mcp.MethodInitialize) {
+		t.cancel(request.ID, context.Cause(ctx))
+	}
+
+	return response, err //nolint:wrapcheck // Transparent wrapper
+}
+
+// cancel sends the cancellation of a request.
+func (t *cancellingTransport) cancel(id mcp.RequestId, cause error) {
+	reason := "request cancelled"
+	if errors.Is(cause, context.DeadlineExceeded) {
+		reason = "request timed out"
+	}
+
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: MethodNotificationCancelled,
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]any{
+					"requestId": id,
+					"reason":    reason,
+				},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+	defer cancel()
+
+	if err := t.Interface.SendNotification(ctx, notification); err != nil {
+		log.Printf("Warning: failed to cancel request %s: %v", id.String(), err)
+	}
+}
+
+// SetRequestHandler sets the handler of requests from the server, if the
+// transport supports them.
+func (t *cancellingTransport) SetRequestHandler(handler transport.RequestHandler) {
+	if bidirectional, ok := t.Interface.(transport.BidirectionalInterface); ok {
+		bidirectional.SetRequestHandler(handler)
+	}
+}
+
+// SetProtocolVersion sets the negotiated protocol version of HTTP transports.
+func (t *cancellingTransport) SetProtocolVersion(version string) {
+	if httpConn, ok := t.Interface.(transport.HTTPConnection); ok {
+		httpConn.SetProtocolVersion(version)
+	}
+}
+
+// SetConnectionLostHandler sets the handler of lost connections, if the
+// transport reports them.
+func (t *cancellingTransport) SetConnectionLostHandler(handler func(error)) {
+	type connectionLostSetter interface {
+		SetConnectionLostHandler(handler func(error))
+	}
+
+	if setter, ok := t.Interface.(connectionLostSetter); ok {
+		setter.SetConnectionLostHandler(handler)
+	}
+}
@@ -0,0 +1,73 @@
+package isolate_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+	"github.com/jkoelker/posuer/pkg/isolate"
+)
+
+func TestNoopCancelsAbandonedRequests(t *testing.T) {
+	t.Parallel()
+
+	cancelled := make(chan mcp.NotificationParams, 1)
+
+	mcpServer := server.NewMCPServer("backend", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool("slow"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	})
+	mcpServer.AddNotificationHandler(
+		isolate.MethodNotificationCancelled,
+		func(_ context.Context, notification mcp.JSONRPCNotification) {
+			cancelled <- notification.Params
+		},
+	)
+
+	httpServer := httptest.NewServer(server.NewStreamableHTTPServer(mcpServer))
+	t.Cleanup(httpServer.Close)
+
+	ctx := context.Background()
+
+	mcpClient, err := isolate.NewNoop().Isolate(config.Server{Name: "remote", URL: httpServer.URL + "/mcp"})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = mcpClient.Close() })
+
+	starter, ok := mcpClient.(*client.Client)
+	require.True(t, ok)
+	require.NoError(t, starter.Start(ctx))
+
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+
+	_, err = mcpClient.Initialize(ctx, request)
+	require.NoError(t, err)
+
+	callCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	call := mcp.CallToolRequest{}
+	call.Params.Name = "slow"
+
+	_, err = mcpClient.CallTool(callCtx, call)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	select {
+	case params := <-cancelled:
+		assert.NotNil(t, params.AdditionalFields["requestId"])
+		assert.Equal(t, "request timed out", params.AdditionalFields["reason"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("the backend was not told the request was cancelled")
+	}
+}
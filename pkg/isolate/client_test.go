@@ -173,3 +173,34 @@ func setupContainerConfig(t *testing.T, cfg config.Server) (config.Server, strin
 
 	return server, cacheDirPath
 }
+
+func TestVolumes(t *testing.T) {
+	t.Parallel()
+
+	volumes, err := isolate.Volumes(config.Server{Name: "test", Command: "echo"})
+	require.NoError(t, err)
+	assert.Nil(t, volumes, "servers not run in a container have no volumes")
+
+	volumes, err = isolate.Volumes(config.Server{
+		Name:    "test",
+		Command: "echo",
+		Container: &config.Container{
+			Image:   "alpine:latest",
+			Volumes: map[string]string{"/home/user/project": "/project"},
+			WorkDir: "/project",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"/home/user/project": "/project"}, volumes)
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	volumes, err = isolate.Volumes(config.Server{
+		Name:      "test",
+		Command:   "echo",
+		Container: &config.Container{Image: "alpine:latest"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{cwd: config.DefaultContainerWorkDir}, volumes)
+}
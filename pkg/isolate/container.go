@@ -26,6 +26,7 @@ var ErrNoContainerRuntime = errors.New("no container runtime found")
 // Container implements the Isolator interface using containers.
 type Container struct {
 	runtime string
+	options []client.ClientOption
 	once    sync.Once
 }
 
@@ -36,6 +37,13 @@ func WithContainerRuntime(runtime string) func(*Container) {
 	}
 }
 
+// WithClientOptions specifies the options of the clients created.
+func WithClientOptions(options ...client.ClientOption) func(*Container) {
+	return func(isolator *Container) {
+		isolator.options = options
+	}
+}
+
 // NewContainer creates a new Container.
 func NewContainer(options ...func(*Container)) (*Container, error) {
 	isolator := &Container{}
@@ -58,7 +66,7 @@ func (c *Container) Isolate(cfg config.Server) (client.MCPClient, error) {
 		cfg.Container == nil ||
 		cfg.Container.IsDisabled() ||
 		!cfg.Container.IsConfigured() {
-		return NewNoop().Isolate(cfg)
+		return NewNoop(c.options...).Isolate(cfg)
 	}
 
 	server := cfg.Clone()
@@ -88,7 +96,7 @@ func (c *Container) Isolate(cfg config.Server) (client.MCPClient, error) {
 	server.Args = args
 	server.Container = nil
 
-	return NewNoop().Isolate(server)
+	return NewNoop(c.options...).Isolate(server)
 }
 
 // detectRuntime returns the available container runtime and its path.
@@ -83,9 +83,9 @@ func DefaultVolumesForCommand(command string) (map[string]string, error) {
 	return volumes, nil
 }
 
-func noopIsolator(cfg config.Server) (client.MCPClient, error) {
+func noopIsolator(cfg config.Server, options []client.ClientOption) (client.MCPClient, error) {
 	// No isolation, return the original config
-	client, err := NewNoop().Isolate(cfg)
+	client, err := NewNoop(options...).Isolate(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create MCP client: %w", err)
 	}
@@ -93,9 +93,9 @@ func noopIsolator(cfg config.Server) (client.MCPClient, error) {
 	return client, nil
 }
 
-func containerIsolator(cfg config.Server) (client.MCPClient, error) {
+func containerIsolator(cfg config.Server, options []client.ClientOption) (client.MCPClient, error) {
 	// Container isolation, return the container isolator
-	isolator, err := NewContainer()
+	isolator, err := NewContainer(WithClientOptions(options...))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create container isolator: %w", err)
 	}
@@ -108,7 +108,18 @@ func containerIsolator(cfg config.Server) (client.MCPClient, error) {
 	return client, nil
 }
 
-func defaultContainerIsolator(cfg config.Server) (client.MCPClient, error) {
+func defaultContainerIsolator(cfg config.Server, options []client.ClientOption) (client.MCPClient, error) {
+	server, err := withContainerDefaults(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return containerIsolator(server, options)
+}
+
+// withContainerDefaults returns the server with the default image, volumes
+// and working directory of its container set.
+func withContainerDefaults(cfg config.Server) (config.Server, error) {
 	server := cfg.Clone()
 
 	// Ensure Container is initialized
@@ -133,7 +144,7 @@ func defaultContainerIsolator(cfg config.Server) (client.MCPClient, error) {
 	// Get default volumes for the command
 	volumes, err := DefaultVolumesForCommand(cfg.Command)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get default volumes for command %s: %w", cfg.Command, err)
+		return config.Server{}, fmt.Errorf("failed to get default volumes for command %s: %w", cfg.Command, err)
 	}
 
 	// Add each volume mapping
@@ -150,7 +161,7 @@ func defaultContainerIsolator(cfg config.Server) (client.MCPClient, error) {
 			// Get the current working directory
 			cwd, err := os.Getwd()
 			if err != nil {
-				return nil, fmt.Errorf("failed to get current working directory: %w", err)
+				return config.Server{}, fmt.Errorf("failed to get current working directory: %w", err)
 			}
 
 			// Add the current working directory to the volumes
@@ -158,23 +169,47 @@ func defaultContainerIsolator(cfg config.Server) (client.MCPClient, error) {
 		}
 	}
 
-	return containerIsolator(server)
+	return server, nil
 }
 
-// Client creates an MCP client using the appropriate isolator for the config.
-func Client(cfg config.Server) (client.MCPClient, error) {
+// Client creates an MCP client using the appropriate isolator for the config,
+// with the options.
+func Client(cfg config.Server, options ...client.ClientOption) (client.MCPClient, error) {
 	// Decide which isolation strategy to use
+	if containerized(cfg) {
+		return defaultContainerIsolator(cfg, options)
+	}
+
+	return noopIsolator(cfg, options)
+}
+
+// containerized returns true if the server is run in a container.
+func containerized(cfg config.Server) bool {
 	switch {
 	case cfg.Container != nil && cfg.Container.IsDisabled():
-		return noopIsolator(cfg)
+		return false
 
 	case cfg.Container != nil && cfg.Container.IsConfigured():
-		return defaultContainerIsolator(cfg)
-
-	case DefaultImageForCommand(cfg.Command) != "":
-		return defaultContainerIsolator(cfg)
+		return true
 
 	default:
-		return noopIsolator(cfg)
+		return DefaultImageForCommand(cfg.Command) != ""
+	}
+}
+
+// Volumes returns the host paths mounted in the container the server is run
+// in, mapped to their paths in the container, or nil if it is not run in a
+// container.
+func Volumes(cfg config.Server) (map[string]string, error) {
+	// Container commands are run as they are
+	if !containerized(cfg) || IsContainerCommand(cfg.Command) {
+		return nil, nil //nolint:nilnil // No volumes without a container
 	}
+
+	server, err := withContainerDefaults(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return server.Container.Volumes, nil
 }
@@ -1,30 +1,34 @@
 package isolate
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 
 	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
 
 	"github.com/jkoelker/posuer/pkg/config"
+	"github.com/jkoelker/posuer/pkg/oauth"
 )
 
 // ErrUnsupportedServerType is returned when an unsupported server type is encountered.
 var ErrUnsupportedServerType = errors.New("unsupported server type")
 
 // Noop implements the Isolator interface without isolation.
-type Noop struct{}
+type Noop struct {
+	options []client.ClientOption
+}
 
-// NewNoop creates a new Noop.
-func NewNoop() *Noop {
-	return &Noop{}
+// NewNoop creates a new Noop, creating clients with the options.
+func NewNoop(options ...client.ClientOption) *Noop {
+	return &Noop{options: options}
 }
 
-// Isolate creates an MCP client without isolation.
+// Isolate creates an MCP client without isolation. Requests abandoned by
+// the caller are cancelled on the server.
 func (n *Noop) Isolate(cfg config.Server) (client.MCPClient, error) {
-	var err error
-
 	var mcpClient client.MCPClient
 
 	switch cfg.ServerType() {
@@ -42,18 +46,116 @@ func (n *Noop) Isolate(cfg config.Server) (client.MCPClient, error) {
 			cfg.Env,
 		)
 
-		mcpClient, err = client.NewStdioMCPClient(cfg.Command, envSlice, cfg.Args...)
-		if err != nil {
+		stdio := transport.NewStdio(cfg.Command, envSlice, cfg.Args...)
+		if err := stdio.Start(context.Background()); err != nil {
 			return nil, fmt.Errorf("failed to create Stdio MCP client: %w", err)
 		}
+
+		mcpClient = client.NewClient(newCancellingTransport(stdio), n.options...)
 	case config.ServerTypeSSE:
-		mcpClient, err = client.NewSSEMCPClient(cfg.URL)
+		options, err := sseOptions(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		sse, err := transport.NewSSE(cfg.URL, options...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create SSE MCP client: %w", err)
 		}
+
+		mcpClient = client.NewClient(newCancellingTransport(sse), n.options...)
+	case config.ServerTypeHTTP:
+		options, err := streamableHTTPOptions(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		streamable, err := transport.NewStreamableHTTP(cfg.URL, options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Streamable HTTP MCP client: %w", err)
+		}
+
+		mcpClient = client.NewClient(newCancellingTransport(streamable), n.options...)
 	default:
 		return nil, fmt.Errorf("%w: %s", ErrUnsupportedServerType, cfg.ServerType())
 	}
 
 	return mcpClient, nil
 }
+
+// sseOptions returns the SSE transport options for the server.
+func sseOptions(cfg config.Server) ([]transport.ClientOption, error) {
+	headers, err := cfg.RequestHeaders()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve request headers: %w", err)
+	}
+
+	options := []transport.ClientOption{client.WithHeaders(headers)}
+
+	if cfg.OAuth.IsEnabled() {
+		oauthConfig, err := oauthConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, transport.WithOAuth(oauthConfig))
+	}
+
+	return options, nil
+}
+
+// streamableHTTPOptions returns the Streamable HTTP transport options for the server.
+func streamableHTTPOptions(cfg config.Server) ([]transport.StreamableHTTPCOption, error) {
+	headers, err := cfg.RequestHeaders()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve request headers: %w", err)
+	}
+
+	options := append(httpOptions(cfg.HTTP), transport.WithHTTPHeaders(headers))
+
+	if cfg.OAuth.IsEnabled() {
+		oauthConfig, err := oauthConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, transport.WithHTTPOAuth(oauthConfig))
+	}
+
+	return options, nil
+}
+
+// oauthConfig returns the OAuth configuration of the server, backed by the
+// credentials cached by `posuer auth`.
+func oauthConfig(cfg config.Server) (transport.OAuthConfig, error) {
+	store, err := oauth.NewServerStore(cfg)
+	if err != nil {
+		return transport.OAuthConfig{}, fmt.Errorf("failed to create oauth store: %w", err)
+	}
+
+	oauthConfig, err := oauth.Config(cfg, store)
+	if err != nil {
+		return transport.OAuthConfig{}, fmt.Errorf("failed to configure oauth: %w", err)
+	}
+
+	return oauthConfig, nil
+}
+
+// httpOptions converts the HTTP configuration to Streamable HTTP transport options.
+func httpOptions(cfg *config.HTTP) []transport.StreamableHTTPCOption {
+	var options []transport.StreamableHTTPCOption
+
+	if cfg == nil {
+		return options
+	}
+
+	if cfg.Timeout > 0 {
+		options = append(options, transport.WithHTTPTimeout(cfg.Timeout.Duration()))
+	}
+
+	if cfg.Listen {
+		options = append(options, transport.WithContinuousListening())
+	}
+
+	return options
+}
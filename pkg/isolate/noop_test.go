@@ -0,0 +1,142 @@
+package isolate_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+	"github.com/jkoelker/posuer/pkg/isolate"
+)
+
+func TestNoopStreamableHTTP(t *testing.T) {
+	t.Parallel()
+
+	mcpServer := server.NewMCPServer("backend", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool("echo"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("hello"), nil
+	})
+
+	httpServer := httptest.NewServer(server.NewStreamableHTTPServer(mcpServer))
+	t.Cleanup(httpServer.Close)
+
+	cfg := config.Server{
+		Name: "remote",
+		URL:  httpServer.URL + "/mcp",
+		HTTP: &config.HTTP{Timeout: config.Duration(5 * time.Second)},
+	}
+
+	ctx := context.Background()
+
+	mcpClient, err := isolate.NewNoop().Isolate(cfg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = mcpClient.Close() })
+
+	starter, ok := mcpClient.(*client.Client)
+	require.True(t, ok)
+	require.NoError(t, starter.Start(ctx))
+
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+
+	_, err = mcpClient.Initialize(ctx, request)
+	require.NoError(t, err)
+
+	tools, err := mcpClient.ListTools(ctx, mcp.ListToolsRequest{})
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+	assert.Equal(t, "echo", tools.Tools[0].Name)
+}
+
+func TestNoopRemoteHeaders(t *testing.T) {
+	t.Parallel()
+
+	for _, serverType := range []config.ServerType{config.ServerTypeSSE, config.ServerTypeHTTP} {
+		t.Run(string(serverType), func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu      sync.Mutex
+				headers []http.Header
+			)
+
+			mcpServer := server.NewMCPServer("backend", "1.0.0")
+
+			var handler http.Handler = server.NewStreamableHTTPServer(mcpServer)
+			if serverType == config.ServerTypeSSE {
+				handler = server.NewSSEServer(mcpServer)
+			}
+
+			httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				headers = append(headers, r.Header.Clone())
+				mu.Unlock()
+
+				handler.ServeHTTP(w, r)
+			}))
+			t.Cleanup(httpServer.Close)
+
+			cfg := config.Server{
+				Name:    "remote",
+				Type:    serverType,
+				URL:     httpServer.URL + "/mcp",
+				Headers: map[string]config.Secret{"X-Api-Key": {Value: "abc123"}},
+				Auth:    &config.Auth{Token: config.Secret{Value: "s3cret"}},
+			}
+
+			if serverType == config.ServerTypeSSE {
+				cfg.URL = httpServer.URL + "/sse"
+			}
+
+			ctx := context.Background()
+
+			mcpClient, err := isolate.NewNoop().Isolate(cfg)
+			require.NoError(t, err)
+
+			t.Cleanup(func() { _ = mcpClient.Close() })
+
+			starter, ok := mcpClient.(*client.Client)
+			require.True(t, ok)
+			require.NoError(t, starter.Start(ctx))
+
+			request := mcp.InitializeRequest{}
+			request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+
+			_, err = mcpClient.Initialize(ctx, request)
+			require.NoError(t, err)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			require.NotEmpty(t, headers)
+
+			for _, header := range headers {
+				assert.Equal(t, "Bearer s3cret", header.Get("Authorization"))
+				assert.Equal(t, "abc123", header.Get("X-Api-Key"))
+			}
+		})
+	}
+}
+
+func TestNoopRemoteHeadersUnresolved(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Server{
+		Name: "remote",
+		URL:  "https://example.com/mcp",
+		Auth: &config.Auth{Token: config.Secret{Env: "POSUER_TEST_TOKEN_MISSING"}},
+	}
+
+	_, err := isolate.NewNoop().Isolate(cfg)
+	assert.ErrorIs(t, err, config.ErrSecretNotFound)
+}
@@ -0,0 +1,202 @@
+// Package manifest caches the capabilities of MCP servers, so that they can be
+// advertised without starting the servers.
+package manifest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// Manifest is the capabilities a server advertised the last time it ran.
+type Manifest struct {
+	// Key identifies the server configuration the manifest was captured from.
+	Key string `json:"key"`
+
+	// Result is the result of initializing the server.
+	Result mcp.InitializeResult `json:"result"`
+
+	Tools     []mcp.Tool             `json:"tools,omitempty"`
+	Prompts   []mcp.Prompt           `json:"prompts,omitempty"`
+	Resources []mcp.Resource         `json:"resources,omitempty"`
+	Templates []mcp.ResourceTemplate `json:"templates,omitempty"`
+}
+
+// Key returns the key identifying how a server is launched. A manifest is
+// stale once the server is launched differently.
+func Key(cfg config.Server) string {
+	launch := struct {
+		Type      config.ServerType `json:"type"`
+		Command   string            `json:"command"`
+		Args      []string          `json:"args"`
+		Env       map[string]string `json:"env"`
+		URL       string            `json:"url"`
+		Container *config.Container `json:"container"`
+	}{
+		Type:      cfg.ServerType(),
+		Command:   cfg.Command,
+		Args:      cfg.Args,
+		Env:       cfg.Env,
+		URL:       cfg.URL,
+		Container: cfg.Container,
+	}
+
+	// Marshaling plain strings, slices and maps cannot fail
+	data, _ := json.Marshal(launch) //nolint:errchkjson // See above
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Capture lists everything an initialized server advertises, each page
+// within the server's list timeout.
+func Capture(
+	ctx context.Context,
+	cfg config.Server,
+	mcpClient client.MCPClient,
+	result *mcp.InitializeResult,
+) (*Manifest, error) {
+	var err error
+
+	manifest := &Manifest{
+		Key:    Key(cfg),
+		Result: *result,
+	}
+
+	timeout := cfg.Timeouts.List
+
+	if result.Capabilities.Tools != nil {
+		if manifest.Tools, err = listAll(ctx, timeout, "tools", toolsPage(mcpClient)); err != nil {
+			return nil, err
+		}
+	}
+
+	if result.Capabilities.Prompts != nil {
+		if manifest.Prompts, err = listAll(ctx, timeout, "prompts", promptsPage(mcpClient)); err != nil {
+			return nil, err
+		}
+	}
+
+	if result.Capabilities.Resources != nil {
+		if manifest.Resources, err = listAll(ctx, timeout, "resources", resourcesPage(mcpClient)); err != nil {
+			return nil, err
+		}
+
+		manifest.Templates, err = listAll(ctx, timeout, "resource templates", templatesPage(mcpClient))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return manifest, nil
+}
+
+// page lists the page of items at a cursor, returning the cursor of the next.
+type page[Item any] func(ctx context.Context, cursor mcp.Cursor) ([]Item, mcp.Cursor, error)
+
+// listAll collects the items of every page, each listed within timeout.
+func listAll[Item any](ctx context.Context, timeout config.Duration, kind string, list page[Item]) ([]Item, error) {
+	var (
+		all    []Item
+		cursor mcp.Cursor
+	)
+
+	for {
+		items, next, err := listPage(ctx, timeout, cursor, list)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", kind, err)
+		}
+
+		all = append(all, items...)
+
+		if next == "" {
+			return all, nil
+		}
+
+		cursor = next
+	}
+}
+
+// listPage lists the page of items at a cursor within timeout, if any.
+func listPage[Item any](
+	ctx context.Context,
+	timeout config.Duration,
+	cursor mcp.Cursor,
+	list page[Item],
+) ([]Item, mcp.Cursor, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout.Duration())
+		defer cancel()
+	}
+
+	return list(ctx, cursor)
+}
+
+// toolsPage returns the pages of the tools of a server.
+func toolsPage(mcpClient client.MCPClient) page[mcp.Tool] {
+	return func(ctx context.Context, cursor mcp.Cursor) ([]mcp.Tool, mcp.Cursor, error) {
+		req := mcp.ListToolsRequest{}
+		req.Params.Cursor = cursor
+
+		result, err := mcpClient.ListTools(ctx, req)
+		if err != nil {
+			return nil, "", err //nolint:wrapcheck // Wrapped by listAll
+		}
+
+		return result.Tools, result.NextCursor, nil
+	}
+}
+
+// promptsPage returns the pages of the prompts of a server.
+func promptsPage(mcpClient client.MCPClient) page[mcp.Prompt] {
+	return func(ctx context.Context, cursor mcp.Cursor) ([]mcp.Prompt, mcp.Cursor, error) {
+		req := mcp.ListPromptsRequest{}
+		req.Params.Cursor = cursor
+
+		result, err := mcpClient.ListPrompts(ctx, req)
+		if err != nil {
+			return nil, "", err //nolint:wrapcheck // Wrapped by listAll
+		}
+
+		return result.Prompts, result.NextCursor, nil
+	}
+}
+
+// resourcesPage returns the pages of the resources of a server.
+func resourcesPage(mcpClient client.MCPClient) page[mcp.Resource] {
+	return func(ctx context.Context, cursor mcp.Cursor) ([]mcp.Resource, mcp.Cursor, error) {
+		req := mcp.ListResourcesRequest{}
+		req.Params.Cursor = cursor
+
+		result, err := mcpClient.ListResources(ctx, req)
+		if err != nil {
+			return nil, "", err //nolint:wrapcheck // Wrapped by listAll
+		}
+
+		return result.Resources, result.NextCursor, nil
+	}
+}
+
+// templatesPage returns the pages of the resource templates of a server.
+func templatesPage(mcpClient client.MCPClient) page[mcp.ResourceTemplate] {
+	return func(ctx context.Context, cursor mcp.Cursor) ([]mcp.ResourceTemplate, mcp.Cursor, error) {
+		req := mcp.ListResourceTemplatesRequest{}
+		req.Params.Cursor = cursor
+
+		result, err := mcpClient.ListResourceTemplates(ctx, req)
+		if err != nil {
+			return nil, "", err //nolint:wrapcheck // Wrapped by listAll
+		}
+
+		return result.ResourceTemplates, result.NextCursor, nil
+	}
+}
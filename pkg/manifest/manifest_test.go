@@ -0,0 +1,105 @@
+package manifest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+	"github.com/jkoelker/posuer/pkg/manifest"
+)
+
+// pagingClient is a client listing its tools and resources one per page.
+type pagingClient struct {
+	client.MCPClient
+
+	deadlines int
+}
+
+// page returns the item at a cursor and the cursor of the next one.
+func page[Item any](ctx context.Context, p *pagingClient, items []Item, cursor mcp.Cursor) ([]Item, mcp.Cursor) {
+	if _, ok := ctx.Deadline(); ok {
+		p.deadlines++
+	}
+
+	index := 0
+	if cursor != "" {
+		index = int(cursor[0] - '0')
+	}
+
+	var next mcp.Cursor
+	if index+1 < len(items) {
+		next = mcp.Cursor(rune('0' + index + 1))
+	}
+
+	return items[index : index+1], next
+}
+
+// ListTools implements the ListTools method of the MCPClient interface.
+func (p *pagingClient) ListTools(ctx context.Context, req mcp.ListToolsRequest) (*mcp.ListToolsResult, error) {
+	tools, next := page(ctx, p, []mcp.Tool{mcp.NewTool("first"), mcp.NewTool("second")}, req.Params.Cursor)
+
+	return &mcp.ListToolsResult{PaginatedResult: mcp.PaginatedResult{NextCursor: next}, Tools: tools}, nil
+}
+
+// ListResources implements the ListResources method of the MCPClient interface.
+func (p *pagingClient) ListResources(
+	ctx context.Context,
+	req mcp.ListResourcesRequest,
+) (*mcp.ListResourcesResult, error) {
+	resources, next := page(ctx, p, []mcp.Resource{
+		mcp.NewResource("test://first", "first"),
+		mcp.NewResource("test://second", "second"),
+		mcp.NewResource("test://third", "third"),
+	}, req.Params.Cursor)
+
+	return &mcp.ListResourcesResult{PaginatedResult: mcp.PaginatedResult{NextCursor: next}, Resources: resources}, nil
+}
+
+// ListResourceTemplates implements the ListResourceTemplates method of the MCPClient interface.
+func (p *pagingClient) ListResourceTemplates(
+	ctx context.Context,
+	req mcp.ListResourceTemplatesRequest,
+) (*mcp.ListResourceTemplatesResult, error) {
+	templates, next := page(ctx, p, []mcp.ResourceTemplate{
+		mcp.NewResourceTemplate("test://{id}", "item"),
+	}, req.Params.Cursor)
+
+	return &mcp.ListResourceTemplatesResult{
+		PaginatedResult:   mcp.PaginatedResult{NextCursor: next},
+		ResourceTemplates: templates,
+	}, nil
+}
+
+func TestCaptureAllPages(t *testing.T) {
+	t.Parallel()
+
+	mcpClient := &pagingClient{}
+	cfg := config.Server{Name: "local", Command: "local-server"}
+	cfg.Timeouts.List = config.Duration(time.Minute)
+
+	result := &mcp.InitializeResult{}
+	result.Capabilities.Tools = &struct {
+		ListChanged bool `json:"listChanged,omitempty"` //nolint:tagliatelle
+	}{}
+	result.Capabilities.Resources = &struct {
+		Subscribe   bool `json:"subscribe,omitempty"`
+		ListChanged bool `json:"listChanged,omitempty"` //nolint:tagliatelle
+	}{}
+
+	captured, err := manifest.Capture(context.Background(), cfg, mcpClient, result)
+	require.NoError(t, err)
+
+	assert.Len(t, captured.Tools, 2)
+	assert.Len(t, captured.Resources, 3)
+	assert.Len(t, captured.Templates, 1)
+	assert.Empty(t, captured.Prompts)
+
+	// Every page is listed within the list timeout
+	assert.Equal(t, 6, mcpClient.deadlines)
+}
@@ -0,0 +1,100 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+const (
+	// storeDirName is the name of the directory holding the manifests.
+	storeDirName = "manifests"
+
+	// storeFilePermissions restricts the manifests to the current user.
+	storeFilePermissions = 0o600
+)
+
+// ErrNotFound is returned when there is no current manifest for a server.
+var ErrNotFound = errors.New("manifest not found")
+
+// Store caches the manifests of servers as files in a directory.
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store caching manifests in dir.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// DefaultDir returns the default directory of the manifests.
+func DefaultDir() (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user cache dir: %w", err)
+	}
+
+	return filepath.Join(cache, config.DefaultConfigDirName, storeDirName), nil
+}
+
+// Load returns the cached manifest of a server. Returns ErrNotFound if there
+// is none, or if it was captured from a server launched differently.
+func (s *Store) Load(cfg config.Server) (*Manifest, error) {
+	path := s.path(cfg.Name)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, cfg.Name)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+
+	if manifest.Key != Key(cfg) {
+		return nil, fmt.Errorf("%w: %s changed since it was captured", ErrNotFound, cfg.Name)
+	}
+
+	return &manifest, nil
+}
+
+// Save caches the manifest of a server.
+func (s *Store) Save(cfg config.Server, manifest *Manifest) error {
+	path := s.path(cfg.Name)
+
+	if err := os.MkdirAll(s.dir, config.DirectoryPermissions); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	// Write to a temporary file first so a crash cannot leave a partial manifest
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, storeFilePermissions); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save manifest: %w", err)
+	}
+
+	return nil
+}
+
+// path returns the path of the manifest of a server.
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, config.FileName(name, ".json"))
+}
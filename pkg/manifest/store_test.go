@@ -0,0 +1,100 @@
+package manifest_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+	"github.com/jkoelker/posuer/pkg/manifest"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	store := manifest.NewStore(t.TempDir())
+	cfg := config.Server{Name: "local", Command: "local-server", Args: []string{"--stdio"}}
+
+	saved := &manifest.Manifest{
+		Key: manifest.Key(cfg),
+		Result: mcp.InitializeResult{
+			ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
+			ServerInfo:      mcp.Implementation{Name: "local", Version: "1.0.0"},
+		},
+		Tools: []mcp.Tool{
+			mcp.NewTool("echo", mcp.WithDescription("Echoes"), mcp.WithString("text")),
+		},
+		Templates: []mcp.ResourceTemplate{
+			mcp.NewResourceTemplate("local://{id}", "Item"),
+		},
+	}
+
+	require.NoError(t, store.Save(cfg, saved))
+
+	loaded, err := store.Load(cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, saved.Result.ServerInfo, loaded.Result.ServerInfo)
+	require.Len(t, loaded.Tools, 1)
+	assert.Equal(t, "echo", loaded.Tools[0].Name)
+	assert.Contains(t, loaded.Tools[0].InputSchema.Properties, "text")
+	require.Len(t, loaded.Templates, 1)
+	assert.Equal(t, "local://{id}", loaded.Templates[0].URITemplate.Raw())
+}
+
+func TestStoreNotFound(t *testing.T) {
+	t.Parallel()
+
+	store := manifest.NewStore(t.TempDir())
+	cfg := config.Server{Name: "local", Command: "local-server"}
+
+	_, err := store.Load(cfg)
+	require.ErrorIs(t, err, manifest.ErrNotFound)
+}
+
+func TestStoreStale(t *testing.T) {
+	t.Parallel()
+
+	store := manifest.NewStore(t.TempDir())
+	cfg := config.Server{Name: "local", Command: "local-server"}
+
+	require.NoError(t, store.Save(cfg, &manifest.Manifest{Key: manifest.Key(cfg)}))
+
+	// Filtering does not change what the server advertises
+	cfg.Disable = &config.Capability{All: true}
+
+	_, err := store.Load(cfg)
+	require.NoError(t, err)
+
+	// Launching it differently does
+	cfg.Args = []string{"--verbose"}
+
+	_, err = store.Load(cfg)
+	require.ErrorIs(t, err, manifest.ErrNotFound)
+}
+
+func TestStoreStaysInDir(t *testing.T) {
+	t.Parallel()
+
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "manifests")
+	store := manifest.NewStore(dir)
+	cfg := config.Server{Name: "../escaped", Command: "local-server"}
+
+	require.NoError(t, store.Save(cfg, &manifest.Manifest{Key: manifest.Key(cfg)}))
+
+	_, err := os.Stat(filepath.Join(parent, "escaped.json"))
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	_, err = store.Load(cfg)
+	require.NoError(t, err)
+}
@@ -0,0 +1,153 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+const (
+	// DefaultCallbackPath is the path of the default loopback redirect URI.
+	DefaultCallbackPath = "/callback"
+
+	// loopbackAddress is the address the default redirect listener binds to.
+	loopbackAddress = "127.0.0.1:0"
+
+	// readHeaderTimeout bounds how long the browser may take to send request headers.
+	readHeaderTimeout = 10 * time.Second
+)
+
+// ErrAuthorizationDenied is returned when the authorization server reports an error.
+var ErrAuthorizationDenied = errors.New("authorization denied")
+
+// callback is the result of the authorization redirect.
+type callback struct {
+	code  string
+	state string
+	err   error
+}
+
+// listenCallback listens for the authorization redirect. Without a redirect
+// URI a random loopback port is used. Returns the listener and the redirect URI.
+func listenCallback(redirectURI string) (net.Listener, string, error) {
+	if redirectURI == "" {
+		listener, err := net.Listen("tcp", loopbackAddress)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to listen for redirect: %w", err)
+		}
+
+		return listener, fmt.Sprintf("http://%s%s", listener.Addr(), DefaultCallbackPath), nil
+	}
+
+	parsed, err := url.Parse(redirectURI)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid redirect uri %s: %w", redirectURI, err)
+	}
+
+	listener, err := net.Listen("tcp", parsed.Host)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to listen for redirect on %s: %w", parsed.Host, err)
+	}
+
+	return listener, redirectURI, nil
+}
+
+// serveCallback serves the redirect URI, calls open once it is being served
+// and waits until the first redirect arrives or the context is canceled.
+func serveCallback(
+	ctx context.Context,
+	listener net.Listener,
+	redirectURI string,
+	open func() error,
+) (callback, error) {
+	parsed, err := url.Parse(redirectURI)
+	if err != nil {
+		return callback{}, fmt.Errorf("invalid redirect uri %s: %w", redirectURI, err)
+	}
+
+	results := make(chan callback, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(parsed.Path, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		result := callback{
+			code:  query.Get("code"),
+			state: query.Get("state"),
+		}
+
+		if errCode := query.Get("error"); errCode != "" {
+			result.err = fmt.Errorf("%w: %s %s", ErrAuthorizationDenied, errCode, query.Get("error_description"))
+
+			http.Error(w, "Authorization failed, you can close this window.", http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Authorization complete, you can close this window.")
+		}
+
+		select {
+		case results <- result:
+		default:
+		}
+	})
+
+	httpServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	go func() {
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Redirect listener error: %v", err)
+		}
+	}()
+
+	defer httpServer.Close()
+
+	if err := open(); err != nil {
+		return callback{}, fmt.Errorf("failed to open authorization url: %w", err)
+	}
+
+	select {
+	case result := <-results:
+		return result, result.err
+
+	case <-ctx.Done():
+		return callback{}, fmt.Errorf("waiting for authorization: %w", ctx.Err())
+	}
+}
+
+// openBrowser prints the authorization URL and tries to open it in the browser.
+func openBrowser(authURL string) error {
+	log.Printf("Open the following URL to authorize Posuer:\n\n    %s\n", authURL)
+
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", authURL)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", authURL)
+	default:
+		cmd = exec.Command("xdg-open", authURL)
+	}
+
+	// The URL has been printed, so failing to open the browser is not fatal
+	if err := cmd.Start(); err != nil {
+		log.Printf("Failed to open browser: %v", err)
+
+		return nil
+	}
+
+	go func() {
+		_ = cmd.Wait()
+	}()
+
+	return nil
+}
@@ -0,0 +1,158 @@
+// Package oauth authorizes Posuer with remote MCP servers using OAuth 2.1.
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+// DefaultClientName is the name Posuer registers itself with.
+const DefaultClientName = "Posuer"
+
+// ErrNotConfigured is returned when OAuth is not configured for a server.
+var ErrNotConfigured = errors.New("oauth not configured")
+
+// Config returns the transport OAuth configuration of the server, completed
+// with the client registration cached in the store.
+func Config(cfg config.Server, store *Store) (transport.OAuthConfig, error) {
+	if !cfg.OAuth.IsEnabled() {
+		return transport.OAuthConfig{}, fmt.Errorf("%w: %s", ErrNotConfigured, cfg.Name)
+	}
+
+	clientSecret, err := cfg.OAuth.ClientSecret.Resolve()
+	if err != nil {
+		return transport.OAuthConfig{}, fmt.Errorf("failed to resolve client secret: %w", err)
+	}
+
+	oauthConfig := transport.OAuthConfig{
+		ClientID:              cfg.OAuth.ClientID,
+		ClientSecret:          clientSecret,
+		RedirectURI:           cfg.OAuth.RedirectURI,
+		Scopes:                cfg.OAuth.Scopes,
+		TokenStore:            store,
+		AuthServerMetadataURL: cfg.OAuth.MetadataURL,
+		PKCEEnabled:           true,
+	}
+
+	if oauthConfig.ClientID != "" {
+		return oauthConfig, nil
+	}
+
+	registration, err := store.Registration()
+	if err != nil {
+		return transport.OAuthConfig{}, err
+	}
+
+	if registration != nil {
+		oauthConfig.ClientID = registration.ClientID
+		oauthConfig.ClientSecret = registration.ClientSecret
+
+		if oauthConfig.RedirectURI == "" {
+			oauthConfig.RedirectURI = registration.RedirectURI
+		}
+	}
+
+	return oauthConfig, nil
+}
+
+// Login runs the interactive authorization code flow for the server and
+// caches the resulting tokens. The client is registered dynamically if no
+// client ID is configured or cached.
+func Login(ctx context.Context, cfg config.Server, opts ...Option) error {
+	options := newOptions(opts...)
+
+	store := options.store
+	if store == nil {
+		var err error
+
+		store, err = NewServerStore(cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	oauthConfig, err := Config(cfg, store)
+	if err != nil {
+		return err
+	}
+
+	oauthConfig.HTTPClient = options.httpClient
+
+	listener, redirectURI, err := listenCallback(oauthConfig.RedirectURI)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	oauthConfig.RedirectURI = redirectURI
+
+	handler := transport.NewOAuthHandler(oauthConfig)
+
+	base, err := baseURL(cfg.URL)
+	if err != nil {
+		return err
+	}
+
+	handler.SetBaseURL(base)
+
+	if handler.GetClientID() == "" {
+		if err := handler.RegisterClient(ctx, options.clientName); err != nil {
+			return fmt.Errorf("failed to register client: %w", err)
+		}
+
+		registration := Registration{
+			ClientID:     handler.GetClientID(),
+			ClientSecret: handler.GetClientSecret(),
+			RedirectURI:  redirectURI,
+		}
+
+		if err := store.SaveRegistration(registration); err != nil {
+			return err
+		}
+	}
+
+	verifier, err := transport.GenerateCodeVerifier()
+	if err != nil {
+		return fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+
+	state, err := transport.GenerateState()
+	if err != nil {
+		return fmt.Errorf("failed to generate state: %w", err)
+	}
+
+	authURL, err := handler.GetAuthorizationURL(ctx, state, transport.GenerateCodeChallenge(verifier))
+	if err != nil {
+		return fmt.Errorf("failed to get authorization url: %w", err)
+	}
+
+	result, err := serveCallback(ctx, listener, redirectURI, func() error {
+		return options.open(authURL)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := handler.ProcessAuthorizationResponse(ctx, result.code, result.state, verifier); err != nil {
+		return fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	return nil
+}
+
+// baseURL returns the scheme and host of the server URL, which is where
+// the protected resource metadata is discovered.
+func baseURL(serverURL string) (string, error) {
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid server url %s: %w", serverURL, err)
+	}
+
+	return fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host), nil
+}
@@ -0,0 +1,339 @@
+package oauth_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+	"github.com/jkoelker/posuer/pkg/oauth"
+)
+
+const fakeClientID = "fake-client"
+
+// fakeAuthServer is an MCP server protected by a minimal OAuth 2.1
+// authorization server supporting discovery, dynamic client registration,
+// PKCE and refresh tokens.
+type fakeAuthServer struct {
+	*httptest.Server
+
+	deny bool
+
+	mu           sync.Mutex
+	redirectURIs []string
+	challenges   map[string]string
+	issued       int
+	access       string
+	refresh      string
+}
+
+func newFakeAuthServer(t *testing.T) *fakeAuthServer {
+	t.Helper()
+
+	fake := &fakeAuthServer{challenges: make(map[string]string)}
+
+	mcpServer := server.NewMCPServer("protected", "1.0.0")
+	streamable := server.NewStreamableHTTPServer(mcpServer)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-protected-resource", fake.protectedResource)
+	mux.HandleFunc("/.well-known/oauth-authorization-server", fake.metadata)
+	mux.HandleFunc("/register", fake.register)
+	mux.HandleFunc("/authorize", fake.authorize)
+	mux.HandleFunc("/token", fake.token)
+	mux.Handle("/mcp", fake.protect(streamable))
+
+	fake.Server = httptest.NewServer(mux)
+	t.Cleanup(fake.Close)
+
+	return fake
+}
+
+func (f *fakeAuthServer) writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func (f *fakeAuthServer) protectedResource(w http.ResponseWriter, _ *http.Request) {
+	f.writeJSON(w, http.StatusOK, map[string]any{
+		"resource":              f.URL + "/mcp",
+		"authorization_servers": []string{f.URL},
+	})
+}
+
+func (f *fakeAuthServer) metadata(w http.ResponseWriter, _ *http.Request) {
+	f.writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                           f.URL,
+		"authorization_endpoint":           f.URL + "/authorize",
+		"token_endpoint":                   f.URL + "/token",
+		"registration_endpoint":            f.URL + "/register",
+		"code_challenge_methods_supported": []string{"S256"},
+	})
+}
+
+func (f *fakeAuthServer) register(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		RedirectURIs []string `json:"redirect_uris"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	f.mu.Lock()
+	f.redirectURIs = request.RedirectURIs
+	f.mu.Unlock()
+
+	f.writeJSON(w, http.StatusCreated, map[string]any{"client_id": fakeClientID})
+}
+
+func (f *fakeAuthServer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	params := url.Values{"state": {query.Get("state")}}
+
+	f.mu.Lock()
+
+	switch {
+	case f.deny:
+		params.Set("error", "access_denied")
+
+	case query.Get("client_id") != fakeClientID || query.Get("code_challenge_method") != "S256":
+		params.Set("error", "invalid_request")
+
+	default:
+		code := fmt.Sprintf("code-%d", len(f.challenges))
+		f.challenges[code] = query.Get("code_challenge")
+
+		params.Set("code", code)
+	}
+
+	f.mu.Unlock()
+
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (f *fakeAuthServer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Form.Get("grant_type") {
+	case "authorization_code":
+		challenge, ok := f.challenges[r.Form.Get("code")]
+		delete(f.challenges, r.Form.Get("code"))
+
+		sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+		if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			f.writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_grant"})
+
+			return
+		}
+
+	case "refresh_token":
+		if f.refresh == "" || r.Form.Get("refresh_token") != f.refresh {
+			f.writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_grant"})
+
+			return
+		}
+
+	default:
+		f.writeJSON(w, http.StatusBadRequest, map[string]any{"error": "unsupported_grant_type"})
+
+		return
+	}
+
+	f.issued++
+	f.access = fmt.Sprintf("access-%d", f.issued)
+	f.refresh = fmt.Sprintf("refresh-%d", f.issued)
+
+	f.writeJSON(w, http.StatusOK, map[string]any{
+		"access_token":  f.access,
+		"token_type":    "bearer",
+		"refresh_token": f.refresh,
+		"expires_in":    3600,
+	})
+}
+
+func (f *fakeAuthServer) protect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		valid := f.access != "" && r.Header.Get("Authorization") == "Bearer "+f.access
+		f.mu.Unlock()
+
+		if !valid {
+			w.Header().Set("WWW-Authenticate", `Bearer resource_metadata="`+f.URL+`/.well-known/oauth-protected-resource"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (f *fakeAuthServer) config() config.Server {
+	return config.Server{
+		Name:  "protected",
+		URL:   f.URL + "/mcp",
+		OAuth: &config.OAuth{Scopes: []string{"mcp"}},
+	}
+}
+
+// follow simulates the user approving the authorization in the browser.
+func follow(authURL string) error {
+	resp, err := http.Get(authURL) //nolint:gosec,noctx // Test URL from the fake server
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+func initialize(ctx context.Context, cfg config.Server, store *oauth.Store) error {
+	oauthConfig, err := oauth.Config(cfg, store)
+	if err != nil {
+		return err
+	}
+
+	mcpClient, err := client.NewOAuthStreamableHttpClient(cfg.URL, oauthConfig)
+	if err != nil {
+		return err
+	}
+	defer mcpClient.Close()
+
+	if err := mcpClient.Start(ctx); err != nil {
+		return err
+	}
+
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+
+	_, err = mcpClient.Initialize(ctx, request)
+
+	return err
+}
+
+func TestLogin(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fake := newFakeAuthServer(t)
+	cfg := fake.config()
+	store := oauth.NewStore(filepath.Join(t.TempDir(), "protected.json"), cfg.URL)
+
+	require.NoError(t, oauth.Login(ctx, cfg, oauth.WithStore(store), oauth.WithOpener(follow)))
+
+	registration, err := store.Registration()
+	require.NoError(t, err)
+	require.NotNil(t, registration)
+	assert.Equal(t, fakeClientID, registration.ClientID)
+	assert.True(t, strings.HasPrefix(registration.RedirectURI, "http://127.0.0.1:"))
+	assert.Equal(t, []string{registration.RedirectURI}, fake.redirectURIs)
+
+	token, err := store.GetToken(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken)
+
+	require.NoError(t, initialize(ctx, cfg, store))
+
+	// Logging in again reuses the cached registration
+	require.NoError(t, oauth.Login(ctx, cfg, oauth.WithStore(store), oauth.WithOpener(follow)))
+
+	token, err = store.GetToken(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", token.AccessToken)
+}
+
+func TestLoginDenied(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeAuthServer(t)
+	fake.deny = true
+
+	cfg := fake.config()
+	store := oauth.NewStore(filepath.Join(t.TempDir(), "protected.json"), cfg.URL)
+
+	err := oauth.Login(context.Background(), cfg, oauth.WithStore(store), oauth.WithOpener(follow))
+	require.ErrorIs(t, err, oauth.ErrAuthorizationDenied)
+
+	_, err = store.GetToken(context.Background())
+	assert.ErrorIs(t, err, transport.ErrNoToken)
+}
+
+func TestRefresh(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fake := newFakeAuthServer(t)
+	cfg := fake.config()
+	store := oauth.NewStore(filepath.Join(t.TempDir(), "protected.json"), cfg.URL)
+
+	require.NoError(t, oauth.Login(ctx, cfg, oauth.WithStore(store), oauth.WithOpener(follow)))
+
+	// Expire the cached token
+	token, err := store.GetToken(ctx)
+	require.NoError(t, err)
+
+	token.ExpiresAt = time.Now().Add(-time.Minute)
+	require.NoError(t, store.SaveToken(ctx, token))
+
+	require.NoError(t, initialize(ctx, cfg, store))
+
+	token, err = store.GetToken(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", token.AccessToken)
+	assert.Equal(t, "refresh-2", token.RefreshToken)
+}
+
+func TestAuthorizationRequired(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeAuthServer(t)
+	cfg := fake.config()
+	store := oauth.NewStore(filepath.Join(t.TempDir(), "protected.json"), cfg.URL)
+
+	err := initialize(context.Background(), cfg, store)
+	require.Error(t, err)
+	assert.True(t, client.IsOAuthAuthorizationRequiredError(err))
+}
+
+func TestConfigNotConfigured(t *testing.T) {
+	t.Parallel()
+
+	_, err := oauth.Config(config.Server{Name: "plain"}, oauth.NewStore(filepath.Join(t.TempDir(), "x"), ""))
+	assert.ErrorIs(t, err, oauth.ErrNotConfigured)
+}
@@ -0,0 +1,60 @@
+package oauth
+
+import (
+	"net/http"
+)
+
+// options holds the options of the login flow.
+type options struct {
+	store      *Store
+	httpClient *http.Client
+	open       func(authURL string) error
+	clientName string
+}
+
+// Option configures the login flow.
+type Option func(*options)
+
+// WithStore sets the store the credentials are cached in,
+// defaults to the server store in the user cache directory.
+func WithStore(store *Store) Option {
+	return func(o *options) {
+		o.store = store
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to talk to the authorization server.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithOpener sets the function directing the user to the authorization URL,
+// defaults to printing the URL and opening it in the browser.
+func WithOpener(open func(authURL string) error) Option {
+	return func(o *options) {
+		o.open = open
+	}
+}
+
+// WithClientName sets the name the client registers itself with.
+func WithClientName(name string) Option {
+	return func(o *options) {
+		o.clientName = name
+	}
+}
+
+// newOptions returns the options with defaults applied.
+func newOptions(opts ...Option) options {
+	opt := options{
+		open:       openBrowser,
+		clientName: DefaultClientName,
+	}
+
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	return opt
+}
@@ -0,0 +1,197 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+
+	"github.com/jkoelker/posuer/pkg/config"
+)
+
+const (
+	// storeDirName is the name of the directory holding the credentials.
+	storeDirName = "oauth"
+
+	// storeFilePermissions restricts the credentials to the current user.
+	storeFilePermissions = 0o600
+)
+
+// Registration is a client registered with an authorization server.
+type Registration struct {
+	// ClientID is the ID of the registered client.
+	ClientID string `json:"client_id"`
+
+	// ClientSecret is the secret of a confidential client.
+	ClientSecret string `json:"client_secret,omitempty"`
+
+	// RedirectURI is the redirect URI the client was registered with.
+	RedirectURI string `json:"redirect_uri,omitempty"`
+}
+
+// credentials are the cached credentials of a server.
+type credentials struct {
+	// URL is the server the credentials were obtained for.
+	URL string `json:"url"`
+
+	Registration *Registration    `json:"registration,omitempty"`
+	Token        *transport.Token `json:"token,omitempty"`
+}
+
+// Store caches the OAuth credentials of a server in a file.
+// It implements the transport.TokenStore interface.
+type Store struct {
+	path string
+	url  string
+	mu   sync.Mutex
+}
+
+// NewStore creates a store caching the credentials for the server URL in path.
+// Credentials cached for a different URL are ignored.
+func NewStore(path, url string) *Store {
+	return &Store{
+		path: path,
+		url:  url,
+	}
+}
+
+// NewServerStore creates a store for the server in the user cache directory.
+func NewServerStore(cfg config.Server) (*Store, error) {
+	path, err := DefaultStorePath(cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStore(path, cfg.URL), nil
+}
+
+// DefaultStorePath returns the default path of the credentials of a server.
+func DefaultStorePath(name string) (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user cache dir: %w", err)
+	}
+
+	return filepath.Join(cache, config.DefaultConfigDirName, storeDirName, config.FileName(name, ".json")), nil
+}
+
+// GetToken implements the transport.TokenStore interface.
+func (s *Store) GetToken(ctx context.Context) (*transport.Token, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	creds, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if creds.Token == nil {
+		return nil, transport.ErrNoToken
+	}
+
+	return creds.Token, nil
+}
+
+// SaveToken implements the transport.TokenStore interface.
+func (s *Store) SaveToken(ctx context.Context, token *transport.Token) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+
+	return s.update(func(creds *credentials) {
+		creds.Token = token
+	})
+}
+
+// Registration returns the cached client registration, or nil if there is none.
+func (s *Store) Registration() (*Registration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	creds, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return creds.Registration, nil
+}
+
+// SaveRegistration caches the client registration.
+func (s *Store) SaveRegistration(registration Registration) error {
+	return s.update(func(creds *credentials) {
+		creds.Registration = &registration
+	})
+}
+
+// update applies fn to the cached credentials and saves them.
+func (s *Store) update(fn func(*credentials)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	creds, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	fn(creds)
+
+	return s.save(creds)
+}
+
+// load reads the cached credentials. Must be called with the lock held.
+func (s *Store) load() (*credentials, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &credentials{URL: s.url}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	var creds credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials %s: %w", s.path, err)
+	}
+
+	// Credentials are only valid for the server they were obtained for
+	if creds.URL != s.url {
+		return &credentials{URL: s.url}, nil
+	}
+
+	return &creds, nil
+}
+
+// save writes the credentials. Must be called with the lock held.
+func (s *Store) save(creds *credentials) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), config.DirectoryPermissions); err != nil {
+		return fmt.Errorf("failed to create credentials directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	// Write to a temporary file first so a crash cannot leave partial credentials
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, storeFilePermissions); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package oauth_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/config"
+	"github.com/jkoelker/posuer/pkg/oauth"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nested", "server.json")
+	store := oauth.NewStore(path, "https://example.com/mcp")
+
+	_, err := store.GetToken(ctx)
+	require.ErrorIs(t, err, transport.ErrNoToken)
+
+	registration, err := store.Registration()
+	require.NoError(t, err)
+	assert.Nil(t, registration)
+
+	require.NoError(t, store.SaveRegistration(oauth.Registration{ClientID: "client"}))
+	require.NoError(t, store.SaveToken(ctx, &transport.Token{AccessToken: "access"}))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// A new store for the same server sees both
+	reopened := oauth.NewStore(path, "https://example.com/mcp")
+
+	token, err := reopened.GetToken(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "access", token.AccessToken)
+
+	registration, err = reopened.Registration()
+	require.NoError(t, err)
+	require.NotNil(t, registration)
+	assert.Equal(t, "client", registration.ClientID)
+
+	// Credentials are ignored once the server URL changes
+	moved := oauth.NewStore(path, "https://example.org/mcp")
+
+	_, err = moved.GetToken(ctx)
+	assert.ErrorIs(t, err, transport.ErrNoToken)
+}
+
+func TestConfigUsesCachedRegistration(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Server{
+		Name:  "remote",
+		URL:   "https://example.com/mcp",
+		OAuth: &config.OAuth{Scopes: []string{"read"}},
+	}
+
+	store := oauth.NewStore(filepath.Join(t.TempDir(), "remote.json"), cfg.URL)
+	require.NoError(t, store.SaveRegistration(oauth.Registration{
+		ClientID:    "registered",
+		RedirectURI: "http://127.0.0.1:4242/callback",
+	}))
+
+	oauthConfig, err := oauth.Config(cfg, store)
+	require.NoError(t, err)
+	assert.Equal(t, "registered", oauthConfig.ClientID)
+	assert.Equal(t, "http://127.0.0.1:4242/callback", oauthConfig.RedirectURI)
+	assert.Equal(t, []string{"read"}, oauthConfig.Scopes)
+	assert.True(t, oauthConfig.PKCEEnabled)
+
+	// A configured client takes precedence over the cached registration
+	cfg.OAuth.ClientID = "static"
+
+	oauthConfig, err = oauth.Config(cfg, store)
+	require.NoError(t, err)
+	assert.Equal(t, "static", oauthConfig.ClientID)
+	assert.Empty(t, oauthConfig.RedirectURI)
+}
+
+func TestDefaultStorePathStaysInDir(t *testing.T) {
+	t.Parallel()
+
+	server, err := oauth.DefaultStorePath("server")
+	require.NoError(t, err)
+
+	escaped, err := oauth.DefaultStorePath("../../escaped")
+	require.NoError(t, err)
+
+	// Credentials of any server name are kept next to the others
+	assert.Equal(t, filepath.Dir(server), filepath.Dir(escaped))
+}
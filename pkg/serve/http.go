@@ -0,0 +1,113 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// DefaultListenAddress is the default address the HTTP transports bind to.
+	DefaultListenAddress = "localhost:8080"
+
+	// DefaultEndpointPath is the default path of the Streamable HTTP endpoint.
+	DefaultEndpointPath = "/mcp"
+
+	// DefaultShutdownTimeout is the default time allowed for in-flight requests to finish.
+	DefaultShutdownTimeout = 5 * time.Second
+
+	// DefaultEventHistory is the default number of events retained per session for resumption.
+	DefaultEventHistory = 256
+
+	// readHeaderTimeout bounds how long a client may take to send request headers.
+	readHeaderTimeout = 10 * time.Second
+)
+
+// HTTP serves an MCP server over the Streamable HTTP transport.
+type HTTP struct {
+	server *server.MCPServer
+	options
+}
+
+// NewHTTP creates a new Streamable HTTP frontend for the MCP server.
+func NewHTTP(mcpServer *server.MCPServer, opts ...Option) *HTTP {
+	return &HTTP{
+		server:  mcpServer,
+		options: newOptions(opts...),
+	}
+}
+
+// Handler returns the HTTP handler serving the Streamable HTTP endpoint.
+func (h *HTTP) Handler() http.Handler {
+	streamable := server.NewStreamableHTTPServer(
+		h.server,
+		server.WithStateful(true),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle(h.endpoint, newEventStore(h.history).Wrap(interceptHTTP(h.interceptor, streamable)))
+
+	return mux
+}
+
+// Serve listens on the configured address and serves clients until the
+// context is canceled, then shuts down gracefully.
+func (h *HTTP) Serve(ctx context.Context) error {
+	listener, err := net.Listen("tcp", h.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", h.address, err)
+	}
+
+	log.Printf("Serving Streamable HTTP on http://%s%s", listener.Addr(), h.endpoint)
+
+	return serveHTTP(ctx, listener, h.Handler(), h.shutdownTimeout)
+}
+
+// serveHTTP serves the handler on the listener until the context is canceled.
+// Request contexts derive from ctx so long-lived streams end on shutdown.
+func serveHTTP(
+	ctx context.Context,
+	listener net.Listener,
+	handler http.Handler,
+	shutdownTimeout time.Duration,
+) error {
+	httpServer := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+
+		return fmt.Errorf("http server error: %w", err)
+
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down http server: %w", err)
+	}
+
+	return nil
+}
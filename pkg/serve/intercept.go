@@ -0,0 +1,190 @@
+package serve
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// stdioSessionID is the ID of the single session served over stdio.
+const stdioSessionID = "stdio"
+
+// Interceptor answers the requests of a client session that the MCP server
+// does not handle itself. It returns false for the messages it leaves to the
+// server, and otherwise the function answering the message. Answering may
+// block on requests to the client, so stdio clients are answered apart from
+// reading their messages.
+type Interceptor func(
+	ctx context.Context,
+	sessionID string,
+	message json.RawMessage,
+) (func() mcp.JSONRPCMessage, bool)
+
+// intercept returns the interceptor's response to the message of a request,
+// restoring the request's body for the server if the message is left to it.
+func intercept(interceptor Interceptor, r *http.Request, sessionID string) (mcp.JSONRPCMessage, bool) {
+	if interceptor == nil || sessionID == "" || r.Method != http.MethodPost {
+		return nil, false
+	}
+
+	body, err := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err != nil {
+		return nil, false
+	}
+
+	answer, ok := interceptor(r.Context(), sessionID, body)
+	if !ok {
+		return nil, false
+	}
+
+	return answer(), true
+}
+
+// interceptHTTP answers the intercepted requests of Streamable HTTP sessions.
+func interceptHTTP(interceptor Interceptor, next http.Handler) http.Handler {
+	if interceptor == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := intercept(interceptor, r, r.Header.Get(server.HeaderKeySessionID))
+		if !ok {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Printf("Failed to write response: %v", err)
+		}
+	})
+}
+
+// interceptSSE answers the intercepted requests of HTTP+SSE sessions, whose
+// responses are sent on their event stream.
+func interceptSSE(interceptor Interceptor, sse *server.SSEServer) http.Handler {
+	if interceptor == nil {
+		return sse
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.URL.Query().Get("sessionId")
+
+		response, ok := intercept(interceptor, r, sessionID)
+		if !ok {
+			sse.ServeHTTP(w, r)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+
+		if err := sse.SendEventToSession(sessionID, response); err != nil {
+			log.Printf("Failed to send response to session %s: %v", sessionID, err)
+		}
+	})
+}
+
+// lockedWriter serializes the messages written to the stdio client, as both
+// the server and the interceptor answer it.
+type lockedWriter struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+// Write implements the io.Writer interface.
+func (w *lockedWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.writer.Write(data) //nolint:wrapcheck // Transparent wrapper
+}
+
+// interceptStdio returns the messages of the stdio client the interceptor
+// leaves to the server, answering the others on stdout. Messages keep being
+// read while intercepted ones are answered, as answering them may wait on the
+// client's responses to requests of the server.
+func interceptStdio(ctx context.Context, interceptor Interceptor, stdin io.Reader, stdout io.Writer) io.Reader {
+	reader, writer := io.Pipe()
+
+	go func() {
+		var answering sync.WaitGroup
+
+		defer answering.Wait()
+
+		lines := bufio.NewReader(stdin)
+
+		for {
+			line, err := lines.ReadBytes('\n')
+			if len(line) > 0 {
+				if werr := answerStdio(ctx, interceptor, line, writer, stdout, &answering); werr != nil {
+					_ = writer.CloseWithError(werr)
+
+					return
+				}
+			}
+
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					err = nil
+				}
+
+				_ = writer.CloseWithError(err)
+
+				return
+			}
+		}
+	}()
+
+	return reader
+}
+
+// answerStdio answers a message of the stdio client in the background if
+// intercepted, and passes it on to the server otherwise.
+func answerStdio(
+	ctx context.Context,
+	interceptor Interceptor,
+	line []byte,
+	forward, stdout io.Writer,
+	answering *sync.WaitGroup,
+) error {
+	answer, ok := interceptor(ctx, stdioSessionID, bytes.TrimSpace(line))
+	if !ok {
+		_, err := forward.Write(line)
+
+		return err //nolint:wrapcheck // Transparent wrapper
+	}
+
+	answering.Add(1)
+
+	go func() {
+		defer answering.Done()
+
+		data, err := json.Marshal(answer())
+		if err != nil {
+			log.Printf("Failed to marshal response: %v", err)
+
+			return
+		}
+
+		if _, err := stdout.Write(append(data, '\n')); err != nil {
+			log.Printf("Failed to write response: %v", err)
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,75 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package serve
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdioAnswersWhileReading(t *testing.T) {
+	t.Parallel()
+
+	// The intercepted request is only answered once the client responded to
+	// a request of the server, as when subscribing starts a lazy backend
+	// asking for the client's roots
+	responded := make(chan struct{})
+
+	interceptor := func(_ context.Context, _ string, message json.RawMessage) (func() mcp.JSONRPCMessage, bool) {
+		var request mcp.JSONRPCRequest
+		if err := json.Unmarshal(message, &request); err != nil || request.Method != "resources/subscribe" {
+			return nil, false
+		}
+
+		return func() mcp.JSONRPCMessage {
+			<-responded
+
+			return mcp.NewJSONRPCResultResponse(request.ID, mcp.EmptyResult{})
+		}, true
+	}
+
+	stdin, client := io.Pipe()
+	stdout, answers := io.Pipe()
+
+	forwarded := bufio.NewReader(interceptStdio(
+		context.Background(),
+		interceptor,
+		stdin,
+		&lockedWriter{writer: answers},
+	))
+
+	go func() {
+		_, _ = client.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{}}` + "\n"))
+		_, _ = client.Write([]byte(`{"jsonrpc":"2.0","id":"roots","result":{"roots":[]}}` + "\n"))
+	}()
+
+	// The client's response is read while the subscription is answered
+	read := make(chan []byte, 1)
+
+	go func() {
+		line, _ := forwarded.ReadBytes('\n')
+		read <- line
+	}()
+
+	select {
+	case line := <-read:
+		assert.Contains(t, string(line), `"id":"roots"`)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "the response of the client was not read")
+	}
+
+	close(responded)
+
+	line, err := bufio.NewReader(stdout).ReadBytes('\n')
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{}}`, string(line))
+
+	require.NoError(t, client.Close())
+}
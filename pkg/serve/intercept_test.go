@@ -0,0 +1,95 @@
+package serve_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/serve"
+)
+
+func TestHTTPInterceptsRequests(t *testing.T) {
+	t.Parallel()
+
+	var sessions []string
+
+	interceptor := func(_ context.Context, sessionID string, message json.RawMessage) (func() mcp.JSONRPCMessage, bool) {
+		var request mcp.JSONRPCRequest
+		if err := json.Unmarshal(message, &request); err != nil || request.Method != "resources/subscribe" {
+			return nil, false
+		}
+
+		return func() mcp.JSONRPCMessage {
+			sessions = append(sessions, sessionID)
+
+			return mcp.NewJSONRPCResultResponse(request.ID, mcp.EmptyResult{})
+		}, true
+	}
+
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	frontend := serve.NewHTTP(mcpServer, serve.WithInterceptor(interceptor))
+
+	httpServer := httptest.NewServer(frontend.Handler())
+	defer httpServer.Close()
+
+	post := func(sessionID, body string) map[string]any {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(
+			ctx,
+			http.MethodPost,
+			httpServer.URL+serve.DefaultEndpointPath,
+			strings.NewReader(body),
+		)
+		require.NoError(t, err)
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+
+		if sessionID != "" {
+			req.Header.Set(server.HeaderKeySessionID, sessionID)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+
+		return response
+	}
+
+	// Requests the interceptor leaves are handled by the server
+	response := post("", `{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "initialize",
+		"params": {"protocolVersion": "2025-03-26", "clientInfo": {"name": "test", "version": "1.0.0"}}
+	}`)
+	assert.Contains(t, response["result"], "serverInfo")
+
+	// Intercepted requests are answered with the interceptor's response
+	response = post("session", `{
+		"jsonrpc": "2.0",
+		"id": 2,
+		"method": "resources/subscribe",
+		"params": {"uri": "test://resource"}
+	}`)
+	assert.InDelta(t, 2, response["id"], 0)
+	assert.Equal(t, map[string]any{}, response["result"])
+	assert.Equal(t, []string{"session"}, sessions)
+}
@@ -0,0 +1,75 @@
+package serve
+
+import "time"
+
+// options holds the settings shared by the frontends.
+type options struct {
+	address         string
+	endpoint        string
+	baseURL         string
+	shutdownTimeout time.Duration
+	history         int
+	interceptor     Interceptor
+}
+
+// Option configures a frontend.
+type Option func(*options)
+
+// WithAddress sets the address the HTTP server listens on.
+func WithAddress(address string) Option {
+	return func(opts *options) {
+		opts.address = address
+	}
+}
+
+// WithEndpointPath sets the path the Streamable HTTP endpoint is served on.
+func WithEndpointPath(endpoint string) Option {
+	return func(opts *options) {
+		opts.endpoint = endpoint
+	}
+}
+
+// WithBaseURL sets the public URL clients use to reach the SSE server.
+func WithBaseURL(baseURL string) Option {
+	return func(opts *options) {
+		opts.baseURL = baseURL
+	}
+}
+
+// WithShutdownTimeout sets how long shutdown waits for in-flight requests.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(opts *options) {
+		opts.shutdownTimeout = timeout
+	}
+}
+
+// WithEventHistory sets how many events are retained per session for resumption.
+func WithEventHistory(events int) Option {
+	return func(opts *options) {
+		opts.history = events
+	}
+}
+
+// WithInterceptor sets the interceptor answering the requests the MCP server
+// does not handle itself.
+func WithInterceptor(interceptor Interceptor) Option {
+	return func(opts *options) {
+		opts.interceptor = interceptor
+	}
+}
+
+// newOptions applies the options over the defaults.
+func newOptions(opts ...Option) options {
+	result := options{
+		address:         DefaultListenAddress,
+		endpoint:        DefaultEndpointPath,
+		shutdownTimeout: DefaultShutdownTimeout,
+		history:         DefaultEventHistory,
+	}
+
+	for _, opt := range opts {
+		opt(&result)
+	}
+
+	return result
+}
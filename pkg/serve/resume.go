@@ -0,0 +1,240 @@
+package serve
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// headerLastEventID is sent by clients resuming an SSE stream.
+	headerLastEventID = "Last-Event-ID"
+
+	// streamRetention is how long an idle session's events are retained.
+	streamRetention = 10 * time.Minute
+)
+
+// event is a single SSE event written to a session's stream.
+type event struct {
+	id   uint64
+	data []byte
+}
+
+// eventStream holds the recent events of a single session.
+type eventStream struct {
+	next    uint64
+	events  []event
+	updated time.Time
+}
+
+// eventStore retains recently written SSE events per session so that a client
+// reconnecting with Last-Event-ID receives the events it missed.
+type eventStore struct {
+	limit   int
+	streams map[string]*eventStream
+	mu      sync.Mutex
+}
+
+// newEventStore creates an event store retaining up to limit events per session.
+func newEventStore(limit int) *eventStore {
+	return &eventStore{
+		limit:   limit,
+		streams: make(map[string]*eventStream),
+	}
+}
+
+// Wrap adds event IDs and resumption to the session streams served by next.
+func (s *eventStore) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.Header.Get(server.HeaderKeySessionID)
+
+		switch {
+		case sessionID == "" || s.limit <= 0:
+			next.ServeHTTP(w, r)
+
+		case r.Method == http.MethodDelete:
+			next.ServeHTTP(w, r)
+			s.forget(sessionID)
+
+		case r.Method == http.MethodGet:
+			writer := &eventWriter{
+				ResponseWriter: w,
+				store:          s,
+				session:        sessionID,
+				replay:         s.since(sessionID, r.Header.Get(headerLastEventID)),
+			}
+
+			next.ServeHTTP(writer, r)
+
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// record stores an event for the session and returns its ID.
+func (s *eventStore) record(sessionID string, data []byte) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	stream, ok := s.streams[sessionID]
+	if !ok {
+		s.prune(now)
+
+		stream = &eventStream{}
+		s.streams[sessionID] = stream
+	}
+
+	stream.next++
+	stream.updated = now
+	stream.events = append(stream.events, event{id: stream.next, data: data})
+
+	if len(stream.events) > s.limit {
+		stream.events = stream.events[len(stream.events)-s.limit:]
+	}
+
+	return stream.next
+}
+
+// since returns the events of a session that came after lastEventID.
+func (s *eventStore) since(sessionID, lastEventID string) []event {
+	if lastEventID == "" {
+		return nil
+	}
+
+	last, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, ok := s.streams[sessionID]
+	if !ok {
+		return nil
+	}
+
+	var missed []event
+
+	for _, evt := range stream.events {
+		if evt.id > last {
+			missed = append(missed, evt)
+		}
+	}
+
+	return missed
+}
+
+// forget drops the events of a terminated session.
+func (s *eventStore) forget(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.streams, sessionID)
+}
+
+// prune drops the streams of sessions that have been idle for too long.
+// Must be called with the lock held.
+func (s *eventStore) prune(now time.Time) {
+	for sessionID, stream := range s.streams {
+		if now.Sub(stream.updated) > streamRetention {
+			delete(s.streams, sessionID)
+		}
+	}
+}
+
+// eventWriter assigns IDs to the SSE events of a session stream as they are
+// written, and replays missed events once the stream is established.
+type eventWriter struct {
+	http.ResponseWriter
+
+	store     *eventStore
+	session   string
+	replay    []event
+	streaming bool
+	pending   []byte
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *eventWriter) WriteHeader(statusCode int) {
+	contentType := w.Header().Get("Content-Type")
+	w.streaming = statusCode == http.StatusOK && strings.HasPrefix(contentType, "text/event-stream")
+
+	w.ResponseWriter.WriteHeader(statusCode)
+
+	if !w.streaming {
+		return
+	}
+
+	for _, evt := range w.replay {
+		if err := w.writeEvent(evt); err != nil {
+			return
+		}
+	}
+
+	w.replay = nil
+}
+
+// Write implements http.ResponseWriter.
+func (w *eventWriter) Write(data []byte) (int, error) {
+	if !w.streaming {
+		n, err := w.ResponseWriter.Write(data)
+		if err != nil {
+			return n, fmt.Errorf("failed to write response: %w", err)
+		}
+
+		return n, nil
+	}
+
+	w.pending = append(w.pending, data...)
+
+	for {
+		end := bytes.Index(w.pending, []byte("\n\n"))
+		if end < 0 {
+			break
+		}
+
+		end += len("\n\n")
+
+		payload := make([]byte, end)
+		copy(payload, w.pending[:end])
+		w.pending = w.pending[end:]
+
+		evt := event{id: w.store.record(w.session, payload), data: payload}
+		if err := w.writeEvent(evt); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(data), nil
+}
+
+// Flush implements http.Flusher.
+func (w *eventWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying response writer for http.ResponseController.
+func (w *eventWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// writeEvent writes a single event prefixed with its ID.
+func (w *eventWriter) writeEvent(evt event) error {
+	if _, err := fmt.Fprintf(w.ResponseWriter, "id: %d\n%s", evt.id, evt.data); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+//nolint:testpackage // Need access to unexported methods for testing
+package serve
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+)
+
+// streamHandler writes the given SSE events as a session stream.
+func streamHandler(events ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+
+		for _, evt := range events {
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", evt)
+		}
+	})
+}
+
+func getStream(handler http.Handler, sessionID, lastEventID string) string {
+	req := httptest.NewRequest(http.MethodGet, DefaultEndpointPath, nil)
+	req.Header.Set(server.HeaderKeySessionID, sessionID)
+
+	if lastEventID != "" {
+		req.Header.Set(headerLastEventID, lastEventID)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec.Body.String()
+}
+
+func TestEventStoreAssignsIDs(t *testing.T) {
+	t.Parallel()
+
+	store := newEventStore(DefaultEventHistory)
+	body := getStream(store.Wrap(streamHandler(`{"a":1}`, `{"b":2}`)), "session", "")
+
+	assert.Equal(t,
+		"id: 1\nevent: message\ndata: {\"a\":1}\n\n"+
+			"id: 2\nevent: message\ndata: {\"b\":2}\n\n",
+		body,
+	)
+}
+
+func TestEventStoreReplaysMissedEvents(t *testing.T) {
+	t.Parallel()
+
+	store := newEventStore(DefaultEventHistory)
+	getStream(store.Wrap(streamHandler(`{"a":1}`, `{"b":2}`)), "session", "")
+
+	body := getStream(store.Wrap(streamHandler(`{"c":3}`)), "session", "1")
+
+	assert.Equal(t,
+		"id: 2\nevent: message\ndata: {\"b\":2}\n\n"+
+			"id: 3\nevent: message\ndata: {\"c\":3}\n\n",
+		body,
+	)
+}
+
+func TestEventStoreIsolatesSessions(t *testing.T) {
+	t.Parallel()
+
+	store := newEventStore(DefaultEventHistory)
+	getStream(store.Wrap(streamHandler(`{"a":1}`)), "first", "")
+
+	body := getStream(store.Wrap(streamHandler()), "second", "0")
+
+	assert.Empty(t, body)
+}
+
+func TestEventStoreLimitsHistory(t *testing.T) {
+	t.Parallel()
+
+	store := newEventStore(1)
+	getStream(store.Wrap(streamHandler(`{"a":1}`, `{"b":2}`)), "session", "")
+
+	body := getStream(store.Wrap(streamHandler()), "session", "0")
+
+	assert.Equal(t, "id: 2\nevent: message\ndata: {\"b\":2}\n\n", body)
+}
+
+func TestEventStoreForgetsTerminatedSessions(t *testing.T) {
+	t.Parallel()
+
+	store := newEventStore(DefaultEventHistory)
+	getStream(store.Wrap(streamHandler(`{"a":1}`)), "session", "")
+
+	req := httptest.NewRequest(http.MethodDelete, DefaultEndpointPath, nil)
+	req.Header.Set(server.HeaderKeySessionID, "session")
+	store.Wrap(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Empty(t, store.since("session", "0"))
+}
+
+func TestParseTransport(t *testing.T) {
+	t.Parallel()
+
+	transport, err := ParseTransport("http")
+	assert.NoError(t, err)
+	assert.Equal(t, TransportHTTP, transport)
+
+	_, err = ParseTransport("carrier-pigeon")
+	assert.ErrorIs(t, err, ErrUnsupportedTransport)
+}
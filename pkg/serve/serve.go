@@ -0,0 +1,62 @@
+// Package serve exposes an MCP server to clients over the supported transports.
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Transport represents the transport used to expose the interposer to clients.
+type Transport string
+
+const (
+	// TransportStdio serves a single client over stdin/stdout.
+	TransportStdio Transport = "stdio"
+
+	// TransportHTTP serves any number of clients over Streamable HTTP.
+	TransportHTTP Transport = "http"
+
+	// TransportSSE serves any number of clients over the legacy HTTP+SSE transport.
+	TransportSSE Transport = "sse"
+)
+
+// ErrUnsupportedTransport is returned when an unknown transport is requested.
+var ErrUnsupportedTransport = errors.New("unsupported transport")
+
+// ParseTransport validates a transport name.
+func ParseTransport(name string) (Transport, error) {
+	switch transport := Transport(name); transport {
+	case TransportStdio, TransportHTTP, TransportSSE:
+		return transport, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedTransport, name)
+	}
+}
+
+// Stdio serves the MCP server over stdin/stdout until the context is canceled.
+// Only the interceptor of the options applies to stdio.
+func Stdio(ctx context.Context, mcpServer *server.MCPServer, opts ...Option) error {
+	stdio := server.NewStdioServer(mcpServer)
+	options := newOptions(opts...)
+
+	var (
+		stdin  io.Reader = os.Stdin
+		stdout io.Writer = os.Stdout
+	)
+
+	if options.interceptor != nil {
+		stdout = &lockedWriter{writer: os.Stdout}
+		stdin = interceptStdio(ctx, options.interceptor, os.Stdin, stdout)
+	}
+
+	if err := stdio.Listen(ctx, stdin, stdout); err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("stdio server error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package serve
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// SSE serves an MCP server over the legacy HTTP+SSE transport (2024-11-05),
+// for clients that do not speak Streamable HTTP.
+type SSE struct {
+	server *server.MCPServer
+	options
+}
+
+// NewSSE creates a new HTTP+SSE frontend for the MCP server.
+func NewSSE(mcpServer *server.MCPServer, opts ...Option) *SSE {
+	return &SSE{
+		server:  mcpServer,
+		options: newOptions(opts...),
+	}
+}
+
+// Handler returns the HTTP handler serving the /sse and /message endpoints.
+func (s *SSE) Handler() http.Handler {
+	var sseOptions []server.SSEOption
+
+	if s.baseURL != "" {
+		sseOptions = append(sseOptions, server.WithBaseURL(s.baseURL))
+	}
+
+	return interceptSSE(s.interceptor, server.NewSSEServer(s.server, sseOptions...))
+}
+
+// Serve listens on the configured address and serves clients until the
+// context is canceled, then shuts down gracefully.
+func (s *SSE) Serve(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.address, err)
+	}
+
+	log.Printf("Serving SSE on http://%s/sse", listener.Addr())
+
+	return serveHTTP(ctx, listener, s.Handler(), s.shutdownTimeout)
+}
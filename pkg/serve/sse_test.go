@@ -0,0 +1,51 @@
+package serve_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jkoelker/posuer/pkg/serve"
+)
+
+func TestSSEAdvertisesBaseURL(t *testing.T) {
+	t.Parallel()
+
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	frontend := serve.NewSSE(mcpServer, serve.WithBaseURL("https://posuer.example.com"))
+
+	httpServer := httptest.NewServer(frontend.Handler())
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"/sse", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: endpoint\n", line)
+
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t,
+		strings.HasPrefix(line, "data: https://posuer.example.com/message?sessionId="),
+		"unexpected endpoint event: %s", line,
+	)
+}